@@ -16,86 +16,204 @@
 package main
 
 import (
+	"context"
 	"effective-mobile/go/config"
+	"effective-mobile/go/internal/activity"
 	"effective-mobile/go/internal/api/http"
+	"effective-mobile/go/internal/auth"
+	"effective-mobile/go/internal/health"
+	"effective-mobile/go/internal/lifecycle"
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/metrics"
+	"effective-mobile/go/internal/outbox"
+	"effective-mobile/go/internal/playlist"
 	"effective-mobile/go/internal/song"
+	"effective-mobile/go/internal/stream"
+	"effective-mobile/go/internal/tracing"
+	"effective-mobile/go/internal/webhook"
+	"effective-mobile/go/migrations"
 	"effective-mobile/go/pkg/database"
+	"errors"
+	"fmt"
+	"io"
 	"os"
-	"os/signal"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/jackc/pgx/v4/pgxpool"
 
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	log "github.com/sirupsen/logrus"
 )
 
 //go:generate swagger generate spec -o ../swagger.json
 
 func main() {
+	if err := run(); err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	log.Info("server exiting")
+}
+
+// run sets the application up and runs it until it is shut down. Every failure is returned
+// here, so that main exits in a single place once the components added so far are stopped.
+func run() error {
 	cfg, err := config.ParseConfig()
 	if err != nil {
-		log.Error("failed to parse config: ", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if err := logging.Setup(cfg); err != nil {
+		return fmt.Errorf("failed to set up logging: %w", err)
+	}
+
+	if cfg.AutoMigrate {
+		if err := runMigrations(cfg); err != nil {
+			return err
+		}
+	}
+
+	// Components are added in the order of their dependencies and stopped in the reverse order.
+	manager := lifecycle.NewManager(cfg)
+
+	if err := addComponents(cfg, manager); err != nil {
+		return errors.Join(err, manager.Abort())
 	}
 
-	if cfg.Mode == "development" {
-		log.SetLevel(log.DebugLevel)
+	if err := manager.Run(context.Background()); err != nil {
+		return fmt.Errorf("application stopped with an error: %w", err)
 	}
 
-	runMigrations(cfg)
+	return nil
+}
 
-	db, err := database.NewPostgresConnection(cfg.DB.ToDSN())
+// addComponents creates the components of the application and adds them to the manager.
+func addComponents(cfg *config.Config, manager *lifecycle.Manager) error {
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
 	if err != nil {
-		log.Error("failed to connect to database: ", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+
+	// Spans are flushed last, so that they include those of the shutdown.
+	manager.Add("tracing", nil, shutdownTracing)
+
+	var configurePool []func(*pgxpool.Config)
+	if tracing.Enabled(cfg) {
+		configurePool = append(configurePool, tracing.ConfigurePool)
+	}
+
+	db, err := database.NewPostgresConnection(cfg.DB.ToDSN(), configurePool...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	manager.Add("database pool", nil, func(ctx context.Context) error {
+		db.Close()
+		return nil
+	})
+
+	if err := metrics.RegisterPool(db); err != nil {
+		return fmt.Errorf("failed to register pool metrics: %w", err)
 	}
 
-	defer db.Close()
+	authRepo := auth.NewAuthRepository(cfg, db)
+	authService := auth.NewAuthService(cfg, authRepo)
+	authMiddleware := auth.NewAuthMiddleware(cfg, authService)
 
 	songRepo := song.NewSongRepository(cfg, db)
 	songService := song.NewSongService(cfg, songRepo)
 	songHandler := song.NewSongHandler(cfg, songService)
 
+	playlistRepo := playlist.NewPlaylistRepository(cfg, db)
+	playlistService := playlist.NewPlaylistService(cfg, playlistRepo)
+	playlistHandler := playlist.NewPlaylistHandler(cfg, playlistService)
+
+	activityRepo := activity.NewActivityRepository(cfg, db)
+	activityService := activity.NewActivityService(cfg, activityRepo)
+	activityHandler := activity.NewActivityHandler(cfg, activityService)
+
+	webhookRepo := webhook.NewWebhookRepository(cfg, db)
+	webhookService := webhook.NewWebhookService(cfg, webhookRepo)
+	webhookHandler := webhook.NewWebhookHandler(cfg, webhookService)
+
+	streamRepo := stream.NewStreamRepository(cfg, db)
+	streamService := stream.NewStreamService(cfg, streamRepo)
+	streamHandler := stream.NewStreamHandler(cfg, streamService)
+
+	outboxRepo := outbox.NewOutboxRepository(cfg, db)
+	relay := outbox.NewRelay(cfg, outboxRepo)
+	for _, name := range cfg.Outbox.Publishers {
+		publisher, err := outbox.NewPublisher(name)
+		if err != nil {
+			return fmt.Errorf("failed to create outbox publisher: %w", err)
+		}
+
+		relay.AddPublisher(publisher)
+
+		// Publishers are closed once the relay no longer publishes to them.
+		if closer, ok := publisher.(io.Closer); ok {
+			manager.Add("outbox publisher "+name, nil, func(ctx context.Context) error {
+				return closer.Close()
+			})
+		}
+	}
+
+	relay.AddPublisher(webhookService)
+
+	healthRepo := health.NewHealthRepository(cfg, db)
+	healthService := health.NewHealthService(cfg, healthRepo)
+	healthService.AddCheck("outbox_relay", true, relay.Check)
+	healthService.AddCheck("webhook_delivery", true, webhookService.Check)
+	healthService.AddCheck("event_stream", true, streamService.Check)
+	// Every instance shares the detail API, so its outage degrades them rather than taking them out.
+	healthService.AddCheck("detail_api", false, songService.CheckDetailAPI)
+	healthHandler := health.NewHealthHandler(cfg, healthService)
+
+	manager.Go("event stream", streamService.Run)
+	manager.Go("webhook delivery", webhookService.Run)
+	manager.Go("outbox relay", relay.Run)
+
 	server := http.NewServer(cfg, http.Handlers{
-		SongHandler: songHandler,
+		AuthMiddleware:  authMiddleware,
+		SongHandler:     songHandler,
+		PlaylistHandler: playlistHandler,
+		ActivityHandler: activityHandler,
+		WebhookHandler:  webhookHandler,
+		StreamHandler:   streamHandler,
+		HealthHandler:   healthHandler,
 	})
-	server.Start()
 
-	log.Info("server started on port ", cfg.HttpPort)
+	// Event streams would otherwise hold the shutdown of the server up until it times out.
+	server.RegisterOnShutdown(streamService.CloseStreams)
 
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
+	manager.Add("http server", func(ctx context.Context) error {
+		server.Start()
+		log.Info("server started on port ", cfg.HttpPort)
 
-	select {
-	case s := <-interrupt:
-		log.Debug("interrupt signal received: ", s.String())
-	case err = <-server.Notify():
-		log.Error("server notify: ", err.Error())
-	}
+		go func() {
+			if err, ok := <-server.Notify(); ok {
+				manager.Fail(fmt.Errorf("server failed: %w", err))
+			}
+		}()
 
-	err = server.Shutdown()
-	if err != nil {
-		log.Error("server shutdown err: ", err)
-	}
+		return nil
+	}, server.Shutdown)
 
-	log.Info("server exiting")
+	manager.OnDrain(healthService.SetDraining)
+
+	return nil
 }
 
-func runMigrations(cfg *config.Config) {
-	m, err := migrate.New(
-		`file://migrations`,
-		cfg.DB.ToDSN())
+func runMigrations(cfg *config.Config) error {
+	m, err := database.NewMigrator(migrations.FS, cfg.DB.ToDSN())
 	if err != nil {
-		log.Error("failed to connect to database: ", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	defer m.Close()
-	if err := m.Up(); err != nil && err.Error() != "no change" {
-		log.Error("failed to execute migrations: ", err)
-		os.Exit(1)
+	if err := m.Up(); err != nil {
+		return fmt.Errorf("failed to execute migrations: %w", err)
 	}
 
 	log.Info("migrations executed successfully")
+	return nil
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"effective-mobile/go/internal/auth"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+func runAPIKey(ctx context.Context, app *app, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected an apikey subcommand: create, list or revoke")
+	}
+
+	switch args[0] {
+	case "create":
+		fs := newFlagSet("apikey create", "")
+		name := fs.String("name", "", "human-readable name of the key")
+		owner := fs.String("owner", "", "user the key acts on behalf of")
+		role := fs.String("role", auth.RoleUser, "role of the key: user or admin")
+		fs.Parse(args[1:])
+
+		if *name == "" || *owner == "" {
+			fs.Usage()
+			return fmt.Errorf("name and owner are required")
+		}
+
+		key, plain, err := app.auth.CreateAPIKey(ctx, *name, *owner, *role)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("created api key %d for %s (%s)\n", key.ID, key.Owner, key.Role)
+		fmt.Printf("key: %s\n", plain)
+		fmt.Println("store it now, it cannot be shown again")
+		return nil
+	case "list":
+		fs := newFlagSet("apikey list", "")
+		all := fs.Bool("all", false, "include revoked keys")
+		fs.Parse(args[1:])
+
+		keys, err := app.auth.GetAPIKeys(ctx, *all)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tOWNER\tROLE\tPREFIX\tCREATED\tLAST USED\tREVOKED")
+		for _, key := range keys {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				key.ID, key.Name, key.Owner, key.Role, key.Prefix,
+				key.CreatedAt.Format(time.DateTime), formatTime(key.LastUsedAt), formatTime(key.RevokedAt))
+		}
+
+		return w.Flush()
+	case "revoke":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: songctl apikey revoke <id>")
+		}
+
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid api key id: %q", args[1])
+		}
+
+		if err := app.auth.RevokeAPIKey(ctx, id); err != nil {
+			return err
+		}
+
+		fmt.Printf("revoked api key %d\n", id)
+		return nil
+	default:
+		return fmt.Errorf("unknown apikey subcommand: %q", args[0])
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.DateTime)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func runEnrich(ctx context.Context, app *app, args []string) error {
+	fs := newFlagSet("enrich", "")
+	songID := fs.Int("id", 0, "refresh a single song")
+	all := fs.Bool("all", false, "refresh every song instead of only the ones lacking lyrics or a link")
+	overwrite := fs.Bool("overwrite", false, "replace existing details instead of filling the missing ones")
+	fs.Parse(args)
+
+	ids := []int{*songID}
+	if *songID == 0 {
+		var err error
+		ids, err = app.songs.GetSongIDs(ctx, !*all)
+		if err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := app.songs.RefreshDetails(ctx, id, *overwrite); err != nil {
+			log.Error("failed to refresh song ", id, ": ", err)
+			failed++
+			continue
+		}
+
+		log.Debug("refreshed song ", id)
+	}
+
+	log.Info("refreshed ", len(ids)-failed, " of ", len(ids), " songs")
+	if failed > 0 {
+		return fmt.Errorf("%d songs failed to refresh", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func runDetectLanguages(ctx context.Context, app *app, args []string) error {
+	fs := newFlagSet("detect-languages", "")
+	songID := fs.Int("id", 0, "analyse the lyrics of a single song")
+	all := fs.Bool("all", false, "analyse every song instead of only the ones never analysed, e.g. after profanity lists change")
+	fs.Parse(args)
+
+	ids := []int{*songID}
+	if *songID == 0 {
+		var err error
+		if *all {
+			ids, err = app.songs.GetSongIDs(ctx, false)
+		} else {
+			ids, err = app.songs.GetUndetectedSongIDs(ctx)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		analysis, err := app.songs.AnalyzeLyrics(ctx, id)
+		if err != nil {
+			log.Error("failed to analyse lyrics of song ", id, ": ", err)
+			failed++
+			continue
+		}
+
+		log.Debug("analysed lyrics of song ", id, ": languages ", analysis.Languages, ", explicit ", analysis.Explicit)
+	}
+
+	log.Info("analysed lyrics of ", len(ids)-failed, " of ", len(ids), " songs")
+	if failed > 0 {
+		return fmt.Errorf("%d songs failed", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+// songctl is an administrative tool for the song library.
+// It works directly against the database configured the same way as the API server.
+package main
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/auth"
+	"effective-mobile/go/internal/song"
+	"effective-mobile/go/pkg/database"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	log "github.com/sirupsen/logrus"
+)
+
+type app struct {
+	cfg   *config.Config
+	db    *pgxpool.Pool
+	songs *song.SongService
+	auth  *auth.AuthService
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = []command{
+	{"import", "import songs from a JSONL or CSV file", runImport},
+	{"export", "export songs as CSV, JSON or JSONL", runExport},
+	{"enrich", "refresh song details from the detail API", runEnrich},
+	{"detect-languages", "detect languages and explicit content of song lyrics", runDetectLanguages},
+	{"migrate", "apply or roll back database migrations", runMigrate},
+	{"apikey", "create, list and revoke API keys", runAPIKey},
+	{"report", "print a data-quality report of the library", runReport},
+	{"maintain-indexes", "rebuild song indexes and refresh planner statistics", runMaintainIndexes},
+}
+
+func main() {
+	log.SetOutput(os.Stderr)
+
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.ParseConfig()
+	if err != nil {
+		log.Error("failed to parse config: ", err)
+		os.Exit(1)
+	}
+
+	if cfg.Mode == "development" {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		log.Error("failed to connect to database: ", err)
+		os.Exit(1)
+	}
+	defer a.db.Close()
+
+	if err := cmd.run(ctx, a, flag.Args()[1:]); err != nil {
+		log.Error(cmd.name, ": ", err)
+		os.Exit(1)
+	}
+}
+
+func newApp(ctx context.Context, cfg *config.Config) (*app, error) {
+	db, err := database.NewPostgresConnection(cfg.DB.ToDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	songRepo := song.NewSongRepository(cfg, db)
+	authRepo := auth.NewAuthRepository(cfg, db)
+
+	return &app{
+		cfg:   cfg,
+		db:    db,
+		songs: song.NewSongService(cfg, songRepo),
+		auth:  auth.NewAuthService(cfg, authRepo),
+	}, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: songctl <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.usage)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nRun 'songctl <command> -h' for the flags of a command.\n")
+}
+
+// newFlagSet returns a flag set for the command that prints its usage on -h.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: songctl %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"effective-mobile/go/migrations"
+	"effective-mobile/go/pkg/database"
+	"fmt"
+	"strconv"
+)
+
+func runMigrate(ctx context.Context, app *app, args []string) error {
+	fs := newFlagSet("migrate", "up [n] | down [n|all] | to <version> | version | force <version>")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("expected a migrate subcommand")
+	}
+
+	m, err := database.NewMigrator(migrations.FS, app.cfg.DB.ToDSN())
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	arg := fs.Arg(1)
+	switch fs.Arg(0) {
+	case "up":
+		if arg == "" {
+			err = m.Up()
+			break
+		}
+
+		var steps int
+		if steps, err = parseSteps(arg); err == nil {
+			err = m.UpSteps(steps)
+		}
+	case "down":
+		// Rolling back everything drops all data, so it has to be asked for explicitly.
+		switch arg {
+		case "":
+			err = m.Down(1)
+		case "all":
+			err = m.Down(0)
+		default:
+			var steps int
+			if steps, err = parseSteps(arg); err == nil {
+				err = m.Down(steps)
+			}
+		}
+	case "to":
+		var version uint64
+		if version, err = strconv.ParseUint(arg, 10, 32); err != nil {
+			return fmt.Errorf("invalid version: %q", arg)
+		}
+
+		err = m.To(uint(version))
+	case "force":
+		var version int
+		if version, err = strconv.Atoi(arg); err != nil {
+			return fmt.Errorf("invalid version: %q", arg)
+		}
+
+		err = m.Force(version)
+	case "version":
+	default:
+		return fmt.Errorf("unknown migrate subcommand: %q", fs.Arg(0))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version %d, dirty: %t\n", version, dirty)
+	return nil
+}
+
+func parseSteps(arg string) (int, error) {
+	steps, err := strconv.Atoi(arg)
+	if err != nil || steps <= 0 {
+		return 0, fmt.Errorf("invalid number of steps: %q", arg)
+	}
+
+	return steps, nil
+}
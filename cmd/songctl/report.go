@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func runReport(ctx context.Context, app *app, args []string) error {
+	fs := newFlagSet("report", "")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+
+	report, err := app.songs.GetQualityReport(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(report)
+	}
+
+	share := func(n int) string {
+		if report.TotalSongs == 0 {
+			return "-"
+		}
+
+		return fmt.Sprintf("%.1f%%", float64(n)*100/float64(report.TotalSongs))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "total songs\t%d\t\n", report.TotalSongs)
+	fmt.Fprintf(w, "missing lyrics\t%d\t%s\n", report.MissingLyrics, share(report.MissingLyrics))
+	fmt.Fprintf(w, "empty couplets\t%d\t%s\n", report.EmptyCouplets, share(report.EmptyCouplets))
+	fmt.Fprintf(w, "missing link\t%d\t%s\n", report.MissingLink, share(report.MissingLink))
+	fmt.Fprintf(w, "invalid link\t%d\t%s\n", report.InvalidLink, share(report.InvalidLink))
+	fmt.Fprintf(w, "default release date\t%d\t%s\n", report.DefaultReleaseDate, share(report.DefaultReleaseDate))
+	fmt.Fprintf(w, "duplicate songs\t%d\t%s\n", report.DuplicateSongs, share(report.DuplicateSongs))
+
+	return w.Flush()
+}
+
+func runMaintainIndexes(ctx context.Context, app *app, args []string) error {
+	fs := newFlagSet("maintain-indexes", "")
+	fs.Parse(args)
+
+	if err := app.songs.MaintainIndexes(ctx); err != nil {
+		return err
+	}
+
+	log.Info("indexes rebuilt and statistics refreshed")
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/song"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func runImport(ctx context.Context, app *app, args []string) error {
+	fs := newFlagSet("import", "<file|->")
+	format := fs.String("format", song.ImportFormatJSONL, "format of the file: jsonl or csv")
+	mode := fs.String("mode", song.ImportModeAtomic, "import mode: atomic or best_effort")
+	enrich := fs.Bool("enrich", false, "fill missing song details from the detail API")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a single file to import")
+	}
+
+	if *mode != song.ImportModeAtomic && *mode != song.ImportModeBestEffort {
+		return fmt.Errorf("unknown import mode: %q", *mode)
+	}
+
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	reader, err := song.NewImportReader(*format, in)
+	if err != nil {
+		return err
+	}
+
+	report, err := app.songs.ImportSongs(ctx, reader, song.ImportOptions{
+		Mode:   *mode,
+		Enrich: *enrich,
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if !report.Committed {
+		return fmt.Errorf("import rolled back, %d of %d rows failed", report.Failed, report.Total)
+	}
+
+	log.Info("imported ", report.Created, " of ", report.Total, " rows")
+	return nil
+}
+
+func runExport(ctx context.Context, app *app, args []string) error {
+	fs := newFlagSet("export", "")
+	format := fs.String("format", song.ExportFormatJSONL, "format of the export: csv, json or jsonl")
+	columns := fs.String("columns", "", "comma-separated list of columns, all by default")
+	lyrics := fs.String("lyrics", song.LyricsArray, "how to export lyrics: array, joined or none")
+	output := fs.String("o", "-", "output file, stdout by default")
+
+	var filter song.SongFilter
+	fs.Func("song", "filter by song name", stringFlag(&filter.Song))
+	fs.Func("group", "filter by group", stringFlag(&filter.Group))
+	fs.Func("text", "filter by lyrics", stringFlag(&filter.Text))
+	fs.Func("link", "filter by link", stringFlag(&filter.Link))
+	fs.Func("language", "filter by detected language", func(value string) error {
+		language, err := song.NormalizeLanguage(value)
+		if err != nil {
+			return err
+		}
+
+		filter.Language = &language
+		return nil
+	})
+	fs.Func("release-date", "filter by release date (YYYY-MM-DD)", func(value string) error {
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return err
+		}
+
+		filter.ReleaseDate = &date
+		return nil
+	})
+	fs.Func("filter", "filter expression, e.g. 'release_date>=1990-01-01;!has_link'", func(value string) error {
+		expr, err := common.ParseFilter(value, song.SongFilterSchema)
+		if err != nil {
+			return err
+		}
+
+		filter.Expr = expr
+		return nil
+	})
+	fs.Parse(args)
+
+	switch *lyrics {
+	case song.LyricsArray, song.LyricsJoined, song.LyricsNone:
+	default:
+		return fmt.Errorf("unknown lyrics mode: %q", *lyrics)
+	}
+
+	cols, err := song.ParseSongFields(*columns)
+	if err != nil {
+		return err
+	}
+
+	opts := song.ExportOptions{
+		Format:  *format,
+		Columns: cols,
+		Lyrics:  *lyrics,
+	}
+
+	out, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	writer, err := song.NewExportWriter(out, opts)
+	if err != nil {
+		return err
+	}
+
+	exported, err := app.songs.ExportSongs(ctx, filter, writer, opts.WithText())
+	if err != nil {
+		return err
+	}
+
+	log.Info("exported ", exported, " songs")
+	return nil
+}
+
+func stringFlag(target **string) func(string) error {
+	return func(value string) error {
+		*target = &value
+		return nil
+	}
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(path)
+}
+
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+
+	return os.Create(path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
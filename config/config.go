@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -12,7 +13,22 @@ type Config struct {
 	SongDetailAPI string `env:"SONG_DETAIL_API" env-required:"true"`
 	Mode          string `env:"MODE" env-default:"development"`
 
-	DB DBConfig
+	AutoMigrate     bool `env:"AUTO_MIGRATE" env-default:"true"`
+	ImportBatchSize int  `env:"IMPORT_BATCH_SIZE" env-default:"500"`
+	APIKeysRequired bool `env:"API_KEYS_REQUIRED" env-default:"false"`
+
+	LyricsStatsCacheTTL time.Duration `env:"LYRICS_STATS_CACHE_TTL" env-default:"10m"`
+	ProfanityListsDir   string        `env:"PROFANITY_LISTS_DIR"`
+
+	DB               DBConfig
+	DetailAPIBreaker BreakerConfig
+	Health           HealthConfig
+	Log              LogConfig
+	Webhooks         WebhookConfig
+	Outbox           OutboxConfig
+	Events           EventsConfig
+	Tracing          TracingConfig
+	Shutdown         ShutdownConfig
 }
 
 type DBConfig struct {
@@ -23,6 +39,68 @@ type DBConfig struct {
 	Password string `env:"DB_PASSWORD" env-required:"true"`
 }
 
+type BreakerConfig struct {
+	// Threshold is the number of consecutive failures after which requests are stopped.
+	Threshold int `env:"DETAIL_API_BREAKER_THRESHOLD" env-default:"5"`
+	// Cooldown is how long requests are stopped before a trial request is let through.
+	Cooldown time.Duration `env:"DETAIL_API_BREAKER_COOLDOWN" env-default:"30s"`
+}
+
+type HealthConfig struct {
+	// CheckTimeout limits every check of readiness.
+	CheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" env-default:"2s"`
+	// WorkerStaleAfter is how long a background worker may go without completing a round before it is unhealthy.
+	WorkerStaleAfter time.Duration `env:"HEALTH_WORKER_STALE_AFTER" env-default:"1m"`
+}
+
+type LogConfig struct {
+	// Level is the least severe level logged: debug, info, warn or error; debug in development and info otherwise by default.
+	Level string `env:"LOG_LEVEL"`
+}
+
+type WebhookConfig struct {
+	// Timeout limits a single delivery attempt.
+	Timeout     time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+	// RetryBackoff is the delay before the first retry, doubled for every further one.
+	RetryBackoff time.Duration `env:"WEBHOOK_RETRY_BACKOFF" env-default:"30s"`
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"5s"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+	// Retention is how long published events are kept.
+	Retention time.Duration `env:"OUTBOX_RETENTION" env-default:"24h"`
+	// Publishers the events are published with besides webhooks: log, channel.
+	Publishers []string `env:"OUTBOX_PUBLISHERS" env-separator:"," env-default:"log"`
+}
+
+type EventsConfig struct {
+	// HeartbeatInterval is how often idle event streams are sent a comment to keep them open.
+	HeartbeatInterval time.Duration `env:"EVENTS_HEARTBEAT_INTERVAL" env-default:"15s"`
+	// ReplayBuffer is the number of recent events kept for streams resumed with Last-Event-ID.
+	ReplayBuffer int `env:"EVENTS_REPLAY_BUFFER" env-default:"1000"`
+}
+
+type TracingConfig struct {
+	// Exporter of the spans: none, stdout, otlp-file or otlp-http.
+	Exporter string `env:"TRACING_EXPORTER" env-default:"none"`
+	// File the otlp-file exporter appends to.
+	File string `env:"TRACING_FILE" env-default:"traces.jsonl"`
+	// Endpoint is the URL the otlp-http exporter posts to; the OTEL_EXPORTER_OTLP_* variables apply when it is empty.
+	Endpoint string `env:"TRACING_ENDPOINT"`
+	// SampleRatio is the share of traces started here that are sampled.
+	SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+	ServiceName string  `env:"TRACING_SERVICE_NAME" env-default:"song-api"`
+}
+
+type ShutdownConfig struct {
+	// ReadinessDelay is how long the instance reports itself unready before it stops serving.
+	ReadinessDelay time.Duration `env:"SHUTDOWN_READINESS_DELAY" env-default:"5s"`
+	// DrainTimeout limits finishing the requests in flight and stopping the background workers.
+	DrainTimeout time.Duration `env:"SHUTDOWN_DRAIN_TIMEOUT" env-default:"30s"`
+}
+
 func (c *DBConfig) ToDSN() string {
 	q := url.Values{}
 	q.Add("sslmode", "disable")
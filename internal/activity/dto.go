@@ -0,0 +1,39 @@
+package activity
+
+import "effective-mobile/go/internal/song"
+
+// swagger:model SongChartEntryDTO
+type SongChartEntryDTO struct {
+	Rank  int          `json:"rank"`
+	Plays int64        `json:"plays"`
+	Likes int64        `json:"likes"`
+	Song  song.SongDTO `json:"song"`
+}
+
+// swagger:model GroupChartEntryDTO
+type GroupChartEntryDTO struct {
+	Rank  int    `json:"rank"`
+	Group string `json:"group"`
+	Songs int    `json:"songs"`
+	Plays int64  `json:"plays"`
+	Likes int64  `json:"likes"`
+}
+
+func (e *SongChartEntry) ToDTO() SongChartEntryDTO {
+	return SongChartEntryDTO{
+		Rank:  e.Rank,
+		Plays: e.Plays,
+		Likes: e.Likes,
+		Song:  e.Song.ToDTO(),
+	}
+}
+
+func (e *GroupChartEntry) ToDTO() GroupChartEntryDTO {
+	return GroupChartEntryDTO{
+		Rank:  e.Rank,
+		Group: e.Group,
+		Songs: e.Songs,
+		Plays: e.Plays,
+		Likes: e.Likes,
+	}
+}
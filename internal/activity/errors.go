@@ -0,0 +1,8 @@
+package activity
+
+import "errors"
+
+var (
+	ErrSongNotFound = errors.New("song not found")
+	ErrUnauthorized = errors.New("user id is required")
+)
@@ -0,0 +1,218 @@
+package activity
+
+import (
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ActivityHandler struct {
+	cfg     *config.Config
+	service *ActivityService
+}
+
+func NewActivityHandler(cfg *config.Config, service *ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		cfg:     cfg,
+		service: service,
+	}
+}
+
+// swagger:parameters RecordPlay LikeSong UnlikeSong
+type songIDDescription struct {
+	// ID of the song
+	// in: path
+	// required: true
+	ID int `uri:"id" binding:"required" json:"id"`
+}
+
+// swagger:parameters GetSongChart GetGroupChart
+type chartDescription struct {
+	// Time window of the chart
+	// in: query
+	// required: false
+	// enum: day,week,all
+	// default: week
+	Window string `form:"window,default=week" json:"window" binding:"oneof=day week all"`
+	// Metric used to rank the chart
+	// in: query
+	// required: false
+	// enum: plays,likes
+	// default: plays
+	Metric string `form:"metric,default=plays" json:"metric" binding:"oneof=plays likes"`
+	// Number of chart entries
+	// in: query
+	// required: false
+	// default: 10
+	Limit int `form:"limit,default=10" json:"limit" binding:"min=1,max=100"`
+}
+
+// swagger:route POST /songs/:id/plays Activity RecordPlay
+// Record a play of the song
+//
+// responses:
+//
+//	201: Response
+//	400: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *ActivityHandler) RecordPlay(ctx *gin.Context) {
+	var req songIDDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
+	}
+
+	if err := h.service.RecordPlay(ctx, req.ID, common.GetUserID(ctx)); err != nil {
+		h.handleError(ctx, "failed to record play", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, common.Response{Message: "play successfully recorded"})
+}
+
+// swagger:route PUT /songs/:id/like Activity LikeSong
+// Like the song on behalf of the calling user
+//
+// responses:
+//
+//	200: Response
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *ActivityHandler) Like(ctx *gin.Context) {
+	var req songIDDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
+	}
+
+	if err := h.service.Like(ctx, req.ID, common.GetUserID(ctx)); err != nil {
+		h.handleError(ctx, "failed to like song", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.Response{Message: "song successfully liked"})
+}
+
+// swagger:route DELETE /songs/:id/like Activity UnlikeSong
+// Remove the like of the calling user from the song
+//
+// responses:
+//
+//	200: Response
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	500: ErrorResponse
+func (h *ActivityHandler) Unlike(ctx *gin.Context) {
+	var req songIDDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
+	}
+
+	if err := h.service.Unlike(ctx, req.ID, common.GetUserID(ctx)); err != nil {
+		h.handleError(ctx, "failed to unlike song", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.Response{Message: "song successfully unliked"})
+}
+
+// swagger:route GET /charts/songs Activity GetSongChart
+// Get the top songs over a time window
+//
+// responses:
+//
+//	200: SongChartResponse
+//	400: ErrorResponse
+//	500: ErrorResponse
+func (h *ActivityHandler) GetSongChart(ctx *gin.Context) {
+	var req chartDescription
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	entries, err := h.service.GetSongChart(ctx, ChartQuery(req))
+	if err != nil {
+		h.handleError(ctx, "failed to get song chart", err)
+		return
+	}
+
+	entriesDTO := make([]SongChartEntryDTO, 0, len(entries))
+	for _, entry := range entries {
+		entriesDTO = append(entriesDTO, entry.ToDTO())
+	}
+
+	// swagger:response SongChartResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string              `json:"message"`
+			Body    []SongChartEntryDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "chart successfully retrieved",
+		Body:    entriesDTO,
+	})
+}
+
+// swagger:route GET /charts/groups Activity GetGroupChart
+// Get the top groups over a time window
+//
+// responses:
+//
+//	200: GroupChartResponse
+//	400: ErrorResponse
+//	500: ErrorResponse
+func (h *ActivityHandler) GetGroupChart(ctx *gin.Context) {
+	var req chartDescription
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	entries, err := h.service.GetGroupChart(ctx, ChartQuery(req))
+	if err != nil {
+		h.handleError(ctx, "failed to get group chart", err)
+		return
+	}
+
+	entriesDTO := make([]GroupChartEntryDTO, 0, len(entries))
+	for _, entry := range entries {
+		entriesDTO = append(entriesDTO, entry.ToDTO())
+	}
+
+	// swagger:response GroupChartResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string               `json:"message"`
+			Body    []GroupChartEntryDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "chart successfully retrieved",
+		Body:    entriesDTO,
+	})
+}
+
+func (h *ActivityHandler) handleError(ctx *gin.Context, message string, err error) {
+	switch err {
+	case ErrUnauthorized:
+		ctx.JSON(http.StatusUnauthorized, common.FormatErrorResponse(ctx, message, err))
+	case ErrSongNotFound:
+		ctx.JSON(http.StatusNotFound, common.FormatErrorResponse(ctx, message, err))
+	default:
+		logging.FromContext(ctx).Error(message, ": ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, message, err))
+	}
+}
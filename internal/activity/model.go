@@ -0,0 +1,35 @@
+package activity
+
+import "effective-mobile/go/internal/song"
+
+const (
+	WindowDay  = "day"
+	WindowWeek = "week"
+	WindowAll  = "all"
+)
+
+const (
+	MetricPlays = "plays"
+	MetricLikes = "likes"
+)
+
+type ChartQuery struct {
+	Window string
+	Metric string
+	Limit  int
+}
+
+type SongChartEntry struct {
+	Rank  int
+	Plays int64
+	Likes int64
+	Song  *song.SongModel
+}
+
+type GroupChartEntry struct {
+	Rank  int
+	Group string
+	Songs int
+	Plays int64
+	Likes int64
+}
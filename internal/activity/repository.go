@@ -0,0 +1,287 @@
+package activity
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/song"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type ActivityRepository struct {
+	config *config.Config
+	db     *pgxpool.Pool
+}
+
+const (
+	songsTable   = "songs"
+	playsTable   = "song_plays"
+	likesTable   = "song_likes"
+	dailyTable   = "song_activity_daily"
+	totalsTable  = "song_activity_totals"
+	fkViolation  = "23503"
+	maxChartSize = 100
+)
+
+func NewActivityRepository(cfg *config.Config, db *pgxpool.Pool) *ActivityRepository {
+	return &ActivityRepository{
+		config: cfg,
+		db:     db,
+	}
+}
+
+// RecordPlay stores the play event and bumps the rollups in the same transaction,
+// so the charts never need to scan raw events.
+func (r *ActivityRepository) RecordPlay(ctx context.Context, songID int, userID *string) error {
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf(`
+			INSERT INTO %s (song_id, user_id) VALUES ($1, $2)
+			RETURNING played_at::date
+		`, playsTable)
+
+		var day time.Time
+		if err := tx.QueryRow(ctx, query, songID, userID).Scan(&day); err != nil {
+			return err
+		}
+
+		return r.bumpRollups(ctx, tx, songID, day, 1, 0)
+	})
+	if err != nil {
+		return mapError(err)
+	}
+
+	logging.FromContext(ctx).Debug("play recorded for song with ID: ", songID)
+	return nil
+}
+
+// Like marks the song as liked by the user. Liking a song twice is a no-op.
+func (r *ActivityRepository) Like(ctx context.Context, songID int, userID string) error {
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf(`
+			INSERT INTO %s (song_id, user_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+			RETURNING created_at::date
+		`, likesTable)
+
+		var day time.Time
+		err := tx.QueryRow(ctx, query, songID, userID).Scan(&day)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return r.bumpRollups(ctx, tx, songID, day, 0, 1)
+	})
+	if err != nil {
+		return mapError(err)
+	}
+
+	logging.FromContext(ctx).Debug("song with ID ", songID, " liked by ", userID)
+	return nil
+}
+
+// Unlike removes the like of the user. Unliking a song that is not liked is a no-op.
+// The like is taken back from the rollup of the day it was given on.
+func (r *ActivityRepository) Unlike(ctx context.Context, songID int, userID string) error {
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf(`
+			DELETE FROM %s WHERE song_id = $1 AND user_id = $2
+			RETURNING created_at::date
+		`, likesTable)
+
+		var day time.Time
+		err := tx.QueryRow(ctx, query, songID, userID).Scan(&day)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return r.bumpRollups(ctx, tx, songID, day, 0, -1)
+	})
+	if err != nil {
+		return mapError(err)
+	}
+
+	logging.FromContext(ctx).Debug("song with ID ", songID, " unliked by ", userID)
+	return nil
+}
+
+func (r *ActivityRepository) GetSongChart(ctx context.Context, q ChartQuery) ([]*SongChartEntry, error) {
+	source, err := chartSource(q.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := chartMetric(q.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			t.plays,
+			t.likes,
+			s.id,
+			s.song,
+			s."group",
+			s.release_date,
+			s."text",
+			s.link
+		FROM (%[1]s) t
+		JOIN %[2]s s ON s.id = t.song_id
+		WHERE t.%[3]s > 0
+		ORDER BY t.%[3]s DESC, s.id
+		LIMIT $1
+	`, source, songsTable, metric)
+
+	rows, err := r.db.Query(ctx, query, min(maxChartSize, max(1, q.Limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*SongChartEntry
+	for rows.Next() {
+		entry := SongChartEntry{Song: new(song.SongModel)}
+		err = rows.Scan(
+			&entry.Plays,
+			&entry.Likes,
+			&entry.Song.ID,
+			&entry.Song.Song,
+			&entry.Song.Group,
+			&entry.Song.ReleaseDate,
+			&entry.Song.Text,
+			&entry.Song.Link,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Rank = len(entries) + 1
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *ActivityRepository) GetGroupChart(ctx context.Context, q ChartQuery) ([]*GroupChartEntry, error) {
+	source, err := chartSource(q.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := chartMetric(q.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			s."group",
+			COUNT(*),
+			SUM(t.plays),
+			SUM(t.likes)
+		FROM (%[1]s) t
+		JOIN %[2]s s ON s.id = t.song_id
+		GROUP BY s."group"
+		HAVING SUM(t.%[3]s) > 0
+		ORDER BY SUM(t.%[3]s) DESC, s."group"
+		LIMIT $1
+	`, source, songsTable, metric)
+
+	rows, err := r.db.Query(ctx, query, min(maxChartSize, max(1, q.Limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*GroupChartEntry
+	for rows.Next() {
+		var entry GroupChartEntry
+		if err = rows.Scan(&entry.Group, &entry.Songs, &entry.Plays, &entry.Likes); err != nil {
+			return nil, err
+		}
+
+		entry.Rank = len(entries) + 1
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// bumpRollups adds the plays and likes to the rollup of the day and to the totals of the song.
+func (r *ActivityRepository) bumpRollups(ctx context.Context, tx pgx.Tx, songID int, day time.Time, plays, likes int) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (song_id, day, plays, likes)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id, day) DO UPDATE SET
+			plays = %[1]s.plays + EXCLUDED.plays,
+			likes = %[1]s.likes + EXCLUDED.likes
+	`, dailyTable)
+	if _, err := tx.Exec(ctx, query, songID, day, plays, likes); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO %s (song_id, plays, likes)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (song_id) DO UPDATE SET
+			plays = %[1]s.plays + EXCLUDED.plays,
+			likes = %[1]s.likes + EXCLUDED.likes
+	`, totalsTable)
+	_, err := tx.Exec(ctx, query, songID, plays, likes)
+	return err
+}
+
+// chartSource returns a subquery yielding (song_id, plays, likes) for the window.
+func chartSource(window string) (string, error) {
+	switch window {
+	case WindowAll:
+		return fmt.Sprintf(`SELECT song_id, plays, likes FROM %s`, totalsTable), nil
+	case WindowWeek:
+		return fmt.Sprintf(`
+			SELECT song_id, SUM(plays) AS plays, SUM(likes) AS likes
+			FROM %s
+			WHERE day > CURRENT_DATE - 7
+			GROUP BY song_id
+		`, dailyTable), nil
+	case WindowDay:
+		return fmt.Sprintf(`
+			SELECT song_id, plays, likes
+			FROM %s
+			WHERE day = CURRENT_DATE
+		`, dailyTable), nil
+	default:
+		return "", fmt.Errorf("unknown chart window: %q", window)
+	}
+}
+
+func chartMetric(metric string) (string, error) {
+	switch metric {
+	case MetricPlays, MetricLikes:
+		return metric, nil
+	default:
+		return "", fmt.Errorf("unknown chart metric: %q", metric)
+	}
+}
+
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == fkViolation {
+		return ErrSongNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,51 @@
+package activity
+
+import (
+	"context"
+	"effective-mobile/go/config"
+)
+
+type ActivityService struct {
+	config *config.Config
+	repo   *ActivityRepository
+}
+
+func NewActivityService(cfg *config.Config, repo *ActivityRepository) *ActivityService {
+	return &ActivityService{
+		config: cfg,
+		repo:   repo,
+	}
+}
+
+func (s *ActivityService) RecordPlay(ctx context.Context, songID int, userID string) error {
+	var user *string
+	if userID != "" {
+		user = &userID
+	}
+
+	return s.repo.RecordPlay(ctx, songID, user)
+}
+
+func (s *ActivityService) Like(ctx context.Context, songID int, userID string) error {
+	if userID == "" {
+		return ErrUnauthorized
+	}
+
+	return s.repo.Like(ctx, songID, userID)
+}
+
+func (s *ActivityService) Unlike(ctx context.Context, songID int, userID string) error {
+	if userID == "" {
+		return ErrUnauthorized
+	}
+
+	return s.repo.Unlike(ctx, songID, userID)
+}
+
+func (s *ActivityService) GetSongChart(ctx context.Context, q ChartQuery) ([]*SongChartEntry, error) {
+	return s.repo.GetSongChart(ctx, q)
+}
+
+func (s *ActivityService) GetGroupChart(ctx context.Context, q ChartQuery) ([]*GroupChartEntry, error) {
+	return s.repo.GetGroupChart(ctx, q)
+}
@@ -1,7 +1,21 @@
 package http
 
-import "effective-mobile/go/internal/song"
+import (
+	"effective-mobile/go/internal/activity"
+	"effective-mobile/go/internal/auth"
+	"effective-mobile/go/internal/health"
+	"effective-mobile/go/internal/playlist"
+	"effective-mobile/go/internal/song"
+	"effective-mobile/go/internal/stream"
+	"effective-mobile/go/internal/webhook"
+)
 
 type Handlers struct {
-	SongHandler *song.SongHandler
+	AuthMiddleware  *auth.AuthMiddleware
+	SongHandler     *song.SongHandler
+	PlaylistHandler *playlist.PlaylistHandler
+	ActivityHandler *activity.ActivityHandler
+	WebhookHandler  *webhook.WebhookHandler
+	StreamHandler   *stream.StreamHandler
+	HealthHandler   *health.HealthHandler
 }
@@ -1,25 +1,81 @@
 package http
 
 import (
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/metrics"
+	"effective-mobile/go/internal/tracing"
+
 	"github.com/gin-gonic/gin"
 )
 
 func newRouter(handlers Handlers) *gin.Engine {
-	r := gin.Default()
+	// Requests are logged by the logging middleware rather than by gin.
+	r := gin.New()
+	// Handlers pass the gin context on as the context of the request, which carries the trace and the logger.
+	r.ContextWithFallback = true
+	r.Use(tracing.Middleware, metrics.Middleware, logging.Middleware, logging.Recovery)
 
-	r.GET("/healthcheck", healthcheck)
+	r.GET("/livez", handlers.HealthHandler.Livez)
+	r.GET("/readyz", handlers.HealthHandler.Readyz)
+	// Kept for probes configured before /livez existed.
+	r.GET("/healthcheck", handlers.HealthHandler.Livez)
+	r.GET("/metrics", metrics.Handler())
 
-	r.GET("/songs", handlers.SongHandler.GetSongs)
-	r.GET("/songs/:id/lyrics", handlers.SongHandler.GetSongLyrics)
-	r.POST("/songs", handlers.SongHandler.CreateSong)
-	r.DELETE("/songs/:id", handlers.SongHandler.DeleteSong)
-	r.PATCH("/songs/:id", handlers.SongHandler.UpdateSong)
+	api := r.Group("/", handlers.AuthMiddleware.Authenticate)
 
-	return r
-}
+	api.GET("/songs", handlers.SongHandler.GetSongs)
+	api.GET("/songs/export", handlers.SongHandler.ExportSongs)
+	api.GET("/songs/:id", handlers.SongHandler.GetSong)
+	api.GET("/songs/:id/lyrics", handlers.SongHandler.GetSongLyrics)
+	api.GET("/songs/:id/lyrics/stats", handlers.SongHandler.GetLyricsStats)
+	api.POST("/songs/:id/lyrics/couplets", handlers.SongHandler.InsertCouplet)
+	api.GET("/songs/:id/lyrics/couplets/:couplet", handlers.SongHandler.GetCouplet)
+	api.PUT("/songs/:id/lyrics/couplets/:couplet", handlers.SongHandler.ReplaceCouplet)
+	api.DELETE("/songs/:id/lyrics/couplets/:couplet", handlers.SongHandler.DeleteCouplet)
+	api.POST("/songs/:id/lyrics/couplets/:couplet/move", handlers.SongHandler.MoveCouplet)
+	api.GET("/songs/:id/translations", handlers.SongHandler.GetTranslations)
+	api.PUT("/songs/:id/translations/:lang", handlers.SongHandler.ReplaceTranslation)
+	api.DELETE("/songs/:id/translations/:lang", handlers.SongHandler.DeleteTranslation)
+	api.POST("/songs", handlers.SongHandler.CreateSong)
+	api.POST("/songs/import", handlers.SongHandler.ImportSongs)
+	api.DELETE("/songs/:id", handlers.SongHandler.DeleteSong)
+	api.PATCH("/songs/:id", handlers.SongHandler.UpdateSong)
+	api.PUT("/songs/:id/explicit", handlers.SongHandler.SetExplicit)
+	api.DELETE("/songs/:id/explicit", handlers.SongHandler.ClearExplicit)
+	api.PUT("/songs/:id/tags", handlers.SongHandler.ReplaceTags)
+	api.POST("/songs/:id/plays", handlers.ActivityHandler.RecordPlay)
+	api.PUT("/songs/:id/like", handlers.ActivityHandler.Like)
+	api.DELETE("/songs/:id/like", handlers.ActivityHandler.Unlike)
+
+	api.GET("/stats", handlers.SongHandler.GetStats)
+	api.GET("/groups/:group/lyrics/stats", handlers.SongHandler.GetGroupLyricsStats)
+
+	api.GET("/events", handlers.StreamHandler.GetEvents)
 
-func healthcheck(ctx *gin.Context) {
-	ctx.JSON(200, gin.H{
-		"message": "OK",
-	})
+	api.GET("/charts/songs", handlers.ActivityHandler.GetSongChart)
+	api.GET("/charts/groups", handlers.ActivityHandler.GetGroupChart)
+
+	api.GET("/playlists", handlers.PlaylistHandler.GetPlaylists)
+	api.POST("/playlists", handlers.PlaylistHandler.CreatePlaylist)
+	api.GET("/playlists/:id", handlers.PlaylistHandler.GetPlaylist)
+	api.PATCH("/playlists/:id", handlers.PlaylistHandler.UpdatePlaylist)
+	api.DELETE("/playlists/:id", handlers.PlaylistHandler.DeletePlaylist)
+	api.GET("/playlists/:id/songs", handlers.PlaylistHandler.GetPlaylistSongs)
+	api.POST("/playlists/:id/items", handlers.PlaylistHandler.AddItem)
+	api.DELETE("/playlists/:id/items/:item_id", handlers.PlaylistHandler.RemoveItem)
+	api.POST("/playlists/:id/items/:item_id/move", handlers.PlaylistHandler.MoveItem)
+
+	api.GET("/health", handlers.AuthMiddleware.RequireAdmin, handlers.HealthHandler.GetReport)
+
+	webhooks := api.Group("/webhooks", handlers.AuthMiddleware.RequireAdmin)
+	webhooks.GET("", handlers.WebhookHandler.GetSubscriptions)
+	webhooks.POST("", handlers.WebhookHandler.CreateSubscription)
+	webhooks.GET("/:id", handlers.WebhookHandler.GetSubscription)
+	webhooks.PATCH("/:id", handlers.WebhookHandler.UpdateSubscription)
+	webhooks.DELETE("/:id", handlers.WebhookHandler.DeleteSubscription)
+	webhooks.GET("/:id/deliveries", handlers.WebhookHandler.GetDeliveries)
+	webhooks.GET("/:id/deliveries/:delivery_id", handlers.WebhookHandler.GetDelivery)
+	webhooks.POST("/:id/deliveries/:delivery_id/replay", handlers.WebhookHandler.ReplayDelivery)
+
+	return r
 }
@@ -3,9 +3,9 @@ package http
 import (
 	"context"
 	"effective-mobile/go/config"
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
 )
 
 type Server struct {
@@ -29,18 +29,25 @@ func NewServer(cfg *config.Config, handlers Handlers) *Server {
 
 func (s *Server) Start() {
 	go func() {
-		s.notify <- s.server.ListenAndServe()
+		if err := s.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			s.notify <- err
+		}
 		close(s.notify)
 	}()
 }
 
-func (s *Server) Shutdown() error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*1000)
-	defer cancel()
-
+// Shutdown stops accepting connections and waits for the requests in flight until the context is done.
+func (s *Server) Shutdown(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
+// RegisterOnShutdown registers a function called once shutdown begins, which is to end
+// long-lived requests such as event streams.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.server.RegisterOnShutdown(f)
+}
+
+// Notify returns a channel receiving the error the server failed with, closed once it has stopped.
 func (s *Server) Notify() <-chan error {
 	return s.notify
 }
@@ -0,0 +1,10 @@
+package auth
+
+import "errors"
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrAPIKeyRequired = errors.New("api key is required")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrForbidden      = errors.New("admin role is required")
+)
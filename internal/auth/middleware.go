@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key of the caller.
+const APIKeyHeader = "X-API-Key"
+
+type AuthMiddleware struct {
+	cfg     *config.Config
+	service *AuthService
+}
+
+func NewAuthMiddleware(cfg *config.Config, service *AuthService) *AuthMiddleware {
+	return &AuthMiddleware{
+		cfg:     cfg,
+		service: service,
+	}
+}
+
+// Authenticate resolves the caller from the API key. Without a key the request
+// is rejected when keys are required, otherwise the caller is taken from the
+// X-User-ID header as is.
+func (m *AuthMiddleware) Authenticate(ctx *gin.Context) {
+	plain := strings.TrimSpace(ctx.GetHeader(APIKeyHeader))
+	if plain == "" {
+		if m.cfg.APIKeysRequired {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, common.FormatErrorResponse(ctx, "unauthorized", ErrAPIKeyRequired))
+			return
+		}
+
+		if userID := strings.TrimSpace(ctx.GetHeader(common.UserIDHeader)); userID != "" {
+			ctx.Set(common.UserIDKey, userID)
+			ctx.Set(common.UserRoleKey, RoleUser)
+			logging.AddFields(ctx, log.Fields{"user_id": userID})
+		}
+
+		ctx.Next()
+		return
+	}
+
+	key, err := m.service.Authenticate(ctx, plain)
+	switch err {
+	case nil:
+	case ErrInvalidAPIKey:
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, common.FormatErrorResponse(ctx, "unauthorized", err))
+		return
+	default:
+		logging.FromContext(ctx).Error("failed to authenticate api key: ", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to authenticate", err))
+		return
+	}
+
+	ctx.Set(common.UserIDKey, key.Owner)
+	ctx.Set(common.UserRoleKey, key.Role)
+	logging.AddFields(ctx, log.Fields{"user_id": key.Owner, "api_key_id": key.ID})
+	ctx.Next()
+}
+
+// RequireAdmin rejects callers without the admin role. It must run after Authenticate.
+func (m *AuthMiddleware) RequireAdmin(ctx *gin.Context) {
+	if common.GetUserRole(ctx) != RoleAdmin {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, common.FormatErrorResponse(ctx, "forbidden", ErrForbidden))
+		return
+	}
+
+	ctx.Next()
+}
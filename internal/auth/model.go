@@ -0,0 +1,20 @@
+package auth
+
+import "time"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+type APIKeyModel struct {
+	ID         int        `db:"id"`
+	Name       string     `db:"name"`
+	Owner      string     `db:"owner"`
+	Role       string     `db:"role"`
+	Prefix     string     `db:"prefix"`
+	KeyHash    string     `db:"key_hash"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/logging"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type AuthRepository struct {
+	config *config.Config
+	db     *pgxpool.Pool
+}
+
+const apiKeysTable = "api_keys"
+
+func NewAuthRepository(cfg *config.Config, db *pgxpool.Pool) *AuthRepository {
+	return &AuthRepository{
+		config: cfg,
+		db:     db,
+	}
+}
+
+func (r *AuthRepository) CreateAPIKey(ctx context.Context, key *APIKeyModel) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (name, owner, role, prefix, key_hash)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, apiKeysTable)
+
+	err := r.db.QueryRow(ctx, query, key.Name, key.Owner, key.Role, key.Prefix, key.KeyHash).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("api key created with ID: ", key.ID)
+	return nil
+}
+
+// GetActiveAPIKey returns the key with the given hash unless it was revoked.
+func (r *AuthRepository) GetActiveAPIKey(ctx context.Context, keyHash string) (*APIKeyModel, error) {
+	query := fmt.Sprintf(`
+		SELECT id, name, owner, role, prefix, key_hash, created_at, last_used_at, revoked_at
+		FROM %s
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`, apiKeysTable)
+
+	var key APIKeyModel
+	err := r.db.QueryRow(ctx, query, keyHash).Scan(
+		&key.ID,
+		&key.Name,
+		&key.Owner,
+		&key.Role,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *AuthRepository) GetAPIKeys(ctx context.Context, includeRevoked bool) ([]*APIKeyModel, error) {
+	query := fmt.Sprintf(`
+		SELECT id, name, owner, role, prefix, key_hash, created_at, last_used_at, revoked_at
+		FROM %s
+		WHERE $1 OR revoked_at IS NULL
+		ORDER BY id
+	`, apiKeysTable)
+
+	rows, err := r.db.Query(ctx, query, includeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*APIKeyModel
+	for rows.Next() {
+		var key APIKeyModel
+		err = rows.Scan(
+			&key.ID,
+			&key.Name,
+			&key.Owner,
+			&key.Role,
+			&key.Prefix,
+			&key.KeyHash,
+			&key.CreatedAt,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, &key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *AuthRepository) RevokeAPIKey(ctx context.Context, keyID int) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, apiKeysTable)
+
+	tag, err := r.db.Exec(ctx, query, keyID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	logging.FromContext(ctx).Debug("api key revoked with ID: ", keyID)
+	return nil
+}
+
+// TouchAPIKey records the key usage, at most once a minute to spare writes on busy keys.
+func (r *AuthRepository) TouchAPIKey(ctx context.Context, keyID int) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, apiKeysTable)
+
+	_, err := r.db.Exec(ctx, query, keyID)
+	return err
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/logging"
+	"encoding/hex"
+	"fmt"
+)
+
+// keyPrefix marks API keys issued by the service, making leaked keys easy to spot.
+const keyPrefix = "sk_"
+
+type AuthService struct {
+	config *config.Config
+	repo   *AuthRepository
+}
+
+func NewAuthService(cfg *config.Config, repo *AuthRepository) *AuthService {
+	return &AuthService{
+		config: cfg,
+		repo:   repo,
+	}
+}
+
+// CreateAPIKey issues a new key for the owner. The plain key is returned only once,
+// the database keeps its hash.
+func (s *AuthService) CreateAPIKey(ctx context.Context, name, owner, role string) (*APIKeyModel, string, error) {
+	if role != RoleUser && role != RoleAdmin {
+		return nil, "", fmt.Errorf("unknown role: %q", role)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+
+	plain := keyPrefix + hex.EncodeToString(secret)
+	key := &APIKeyModel{
+		Name:    name,
+		Owner:   owner,
+		Role:    role,
+		Prefix:  plain[:len(keyPrefix)+8],
+		KeyHash: hashKey(plain),
+	}
+
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, plain, nil
+}
+
+func (s *AuthService) GetAPIKeys(ctx context.Context, includeRevoked bool) ([]*APIKeyModel, error) {
+	return s.repo.GetAPIKeys(ctx, includeRevoked)
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, keyID int) error {
+	return s.repo.RevokeAPIKey(ctx, keyID)
+}
+
+func (s *AuthService) Authenticate(ctx context.Context, plain string) (*APIKeyModel, error) {
+	key, err := s.repo.GetActiveAPIKey(ctx, hashKey(plain))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.TouchAPIKey(ctx, key.ID); err != nil {
+		logging.FromContext(ctx).Warn("failed to record api key usage: ", err)
+	}
+
+	return key, nil
+}
+
+func hashKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,343 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterOp is an operator of a filter predicate.
+type FilterOp string
+
+const (
+	FilterOpEq       FilterOp = "=="
+	FilterOpNe       FilterOp = "!="
+	FilterOpGt       FilterOp = ">"
+	FilterOpGe       FilterOp = ">="
+	FilterOpLt       FilterOp = "<"
+	FilterOpLe       FilterOp = "<="
+	FilterOpPrefix   FilterOp = "=^"
+	FilterOpContains FilterOp = "=~"
+	FilterOpIn       FilterOp = "=in="
+)
+
+// filterOps is ordered so that longer operators are matched before their prefixes.
+var filterOps = []FilterOp{
+	FilterOpIn,
+	FilterOpEq,
+	FilterOpNe,
+	FilterOpGe,
+	FilterOpLe,
+	FilterOpPrefix,
+	FilterOpContains,
+	FilterOpGt,
+	FilterOpLt,
+}
+
+type FilterType int
+
+const (
+	FilterText FilterType = iota
+	FilterDate
+	FilterInt
+	FilterBool
+)
+
+var filterTypeOps = map[FilterType][]FilterOp{
+	FilterText: {FilterOpEq, FilterOpNe, FilterOpPrefix, FilterOpContains, FilterOpIn},
+	FilterDate: {FilterOpEq, FilterOpNe, FilterOpGt, FilterOpGe, FilterOpLt, FilterOpLe, FilterOpIn},
+	FilterInt:  {FilterOpEq, FilterOpNe, FilterOpGt, FilterOpGe, FilterOpLt, FilterOpLe, FilterOpIn},
+	FilterBool: {FilterOpEq, FilterOpNe},
+}
+
+// FilterSchema lists the fields a filter expression may refer to.
+type FilterSchema map[string]FilterType
+
+const (
+	maxFilterPredicates = 20
+	maxFilterValues     = 100
+	maxFilterValueSize  = 255
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// FilterPredicate is a validated predicate. Values hold string, time.Time, int or bool
+// according to the type of the field; only FilterOpIn has more than one value.
+type FilterPredicate struct {
+	Field  string
+	Type   FilterType
+	Op     FilterOp
+	Values []interface{}
+	Negate bool
+}
+
+// FilterExpr is a conjunction of predicates.
+type FilterExpr []FilterPredicate
+
+// ParseFilter parses a filter expression and validates it against the schema.
+// An empty expression yields an empty FilterExpr.
+//
+// A filter expression is a list of predicates separated by ";", all of which must hold:
+//
+//	release_date>=1990-01-01;group=in=(Massive Attack,Portishead);!has_link
+//
+// A predicate is a field, an operator and a value, optionally preceded by "!" to negate it.
+// Boolean fields may be used on their own. Values containing ";", ",", ")" or surrounding
+// spaces have to be quoted with double quotes, with \" and \\ as escapes.
+//
+// Operators:
+//
+//	==  equals            !=  does not equal
+//	>   greater than      >=  greater than or equal
+//	<   less than         <=  less than or equal
+//	=^  starts with       =~  contains
+//	=in=(a,b)  equals any of the values
+func ParseFilter(input string, schema FilterSchema) (FilterExpr, error) {
+	p := &filterParser{input: input, schema: schema}
+
+	expr := make(FilterExpr, 0)
+	for {
+		p.skipSpaces()
+		if p.done() {
+			break
+		}
+
+		if len(expr) == maxFilterPredicates {
+			return nil, p.errorf("too many predicates, at most %d are allowed", maxFilterPredicates)
+		}
+
+		predicate, err := p.parsePredicate()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = append(expr, *predicate)
+
+		p.skipSpaces()
+		if p.done() {
+			break
+		}
+
+		if !p.consume(";") {
+			return nil, p.errorf(`expected ";"`)
+		}
+	}
+
+	return expr, nil
+}
+
+type filterParser struct {
+	input  string
+	pos    int
+	schema FilterSchema
+}
+
+func (p *filterParser) parsePredicate() (*FilterPredicate, error) {
+	negate := p.consume("!")
+	p.skipSpaces()
+
+	start := p.pos
+	for !p.done() && isFilterFieldChar(p.input[p.pos]) {
+		p.pos++
+	}
+
+	field := p.input[start:p.pos]
+	if field == "" {
+		return nil, p.errorf("expected a field name")
+	}
+
+	fieldType, ok := p.schema[field]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown field %q", field)
+	}
+
+	predicate := &FilterPredicate{Field: field, Type: fieldType, Negate: negate}
+
+	p.skipSpaces()
+	if p.done() || p.peek(";") {
+		if fieldType != FilterBool {
+			return nil, p.errorf("expected an operator after %q", field)
+		}
+
+		predicate.Op = FilterOpEq
+		predicate.Values = []interface{}{true}
+		return predicate, nil
+	}
+
+	for _, op := range filterOps {
+		if p.consume(string(op)) {
+			predicate.Op = op
+			break
+		}
+	}
+
+	if predicate.Op == "" {
+		return nil, p.errorf("expected an operator after %q", field)
+	}
+
+	if !allowsFilterOp(fieldType, predicate.Op) {
+		return nil, p.errorf("operator %q is not supported by %q", predicate.Op, field)
+	}
+
+	var raw []string
+	if predicate.Op == FilterOpIn {
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+
+		raw = list
+	} else {
+		value, err := p.parseValue(";")
+		if err != nil {
+			return nil, err
+		}
+
+		raw = []string{value}
+	}
+
+	for _, value := range raw {
+		typed, err := convertFilterValue(fieldType, value)
+		if err != nil {
+			return nil, p.errorf("invalid value %q of %q: %v", value, field, err)
+		}
+
+		predicate.Values = append(predicate.Values, typed)
+	}
+
+	return predicate, nil
+}
+
+func (p *filterParser) parseList() ([]string, error) {
+	p.skipSpaces()
+	if !p.consume("(") {
+		return nil, p.errorf(`expected "("`)
+	}
+
+	var values []string
+	for {
+		if len(values) == maxFilterValues {
+			return nil, p.errorf("too many values, at most %d are allowed", maxFilterValues)
+		}
+
+		value, err := p.parseValue(",)")
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+
+		p.skipSpaces()
+		if p.consume(")") {
+			return values, nil
+		}
+
+		if !p.consume(",") {
+			return nil, p.errorf(`expected "," or ")"`)
+		}
+	}
+}
+
+// parseValue reads a quoted value or an unquoted one running up to any of the stop characters.
+func (p *filterParser) parseValue(stop string) (string, error) {
+	p.skipSpaces()
+
+	var value string
+	if p.consume(`"`) {
+		var b strings.Builder
+		for {
+			if p.done() {
+				return "", p.errorf("unterminated quoted value")
+			}
+
+			c := p.input[p.pos]
+			p.pos++
+
+			if c == '"' {
+				break
+			}
+
+			if c == '\\' && !p.done() {
+				c = p.input[p.pos]
+				p.pos++
+			}
+
+			b.WriteByte(c)
+		}
+
+		value = b.String()
+	} else {
+		start := p.pos
+		for !p.done() && !strings.ContainsRune(stop, rune(p.input[p.pos])) {
+			p.pos++
+		}
+
+		value = strings.TrimSpace(p.input[start:p.pos])
+		if value == "" {
+			return "", p.errorf("expected a value")
+		}
+	}
+
+	if len(value) > maxFilterValueSize {
+		return "", p.errorf("value is longer than %d bytes", maxFilterValueSize)
+	}
+
+	return value, nil
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *filterParser) peek(s string) bool {
+	return strings.HasPrefix(p.input[p.pos:], s)
+}
+
+func (p *filterParser) consume(s string) bool {
+	if p.peek(s) {
+		p.pos += len(s)
+		return true
+	}
+
+	return false
+}
+
+func (p *filterParser) skipSpaces() {
+	for !p.done() && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w at position %d: %s", ErrInvalidFilter, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func isFilterFieldChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_'
+}
+
+func allowsFilterOp(fieldType FilterType, op FilterOp) bool {
+	for _, allowed := range filterTypeOps[fieldType] {
+		if allowed == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func convertFilterValue(fieldType FilterType, value string) (interface{}, error) {
+	switch fieldType {
+	case FilterDate:
+		return time.Parse(time.DateOnly, value)
+	case FilterInt:
+		return strconv.Atoi(value)
+	case FilterBool:
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
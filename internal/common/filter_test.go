@@ -0,0 +1,172 @@
+package common
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFilterSchema = FilterSchema{
+	"song":         FilterText,
+	"release_date": FilterDate,
+	"couplets":     FilterInt,
+	"has_link":     FilterBool,
+}
+
+func TestParseFilter(t *testing.T) {
+	date := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  FilterExpr
+	}{
+		{input: "", want: FilterExpr{}},
+		{input: "   ", want: FilterExpr{}},
+		{
+			input: "song==Angel",
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpEq, Values: []interface{}{"Angel"}}},
+		},
+		{
+			input: "song!=Angel",
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpNe, Values: []interface{}{"Angel"}}},
+		},
+		{
+			input: "release_date>=1990-01-01",
+			want:  FilterExpr{{Field: "release_date", Type: FilterDate, Op: FilterOpGe, Values: []interface{}{date}}},
+		},
+		{
+			input: "release_date>1990-01-01",
+			want:  FilterExpr{{Field: "release_date", Type: FilterDate, Op: FilterOpGt, Values: []interface{}{date}}},
+		},
+		{
+			input: "couplets<=3",
+			want:  FilterExpr{{Field: "couplets", Type: FilterInt, Op: FilterOpLe, Values: []interface{}{3}}},
+		},
+		{
+			input: "couplets<3",
+			want:  FilterExpr{{Field: "couplets", Type: FilterInt, Op: FilterOpLt, Values: []interface{}{3}}},
+		},
+		{
+			input: "song=^Tear",
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpPrefix, Values: []interface{}{"Tear"}}},
+		},
+		{
+			input: "song=~drop",
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpContains, Values: []interface{}{"drop"}}},
+		},
+		{
+			input: "has_link",
+			want:  FilterExpr{{Field: "has_link", Type: FilterBool, Op: FilterOpEq, Values: []interface{}{true}}},
+		},
+		{
+			input: "!has_link",
+			want:  FilterExpr{{Field: "has_link", Type: FilterBool, Op: FilterOpEq, Values: []interface{}{true}, Negate: true}},
+		},
+		{
+			input: "has_link==false",
+			want:  FilterExpr{{Field: "has_link", Type: FilterBool, Op: FilterOpEq, Values: []interface{}{false}}},
+		},
+		{
+			input: " ! song == Angel ; couplets>2 ",
+			want: FilterExpr{
+				{Field: "song", Type: FilterText, Op: FilterOpEq, Values: []interface{}{"Angel"}, Negate: true},
+				{Field: "couplets", Type: FilterInt, Op: FilterOpGt, Values: []interface{}{2}},
+			},
+		},
+		{
+			input: "song=in=(Angel, Teardrop ,Unfinished Sympathy)",
+			want: FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpIn, Values: []interface{}{
+				"Angel", "Teardrop", "Unfinished Sympathy",
+			}}},
+		},
+		{
+			input: "couplets=in=(1,2)",
+			want:  FilterExpr{{Field: "couplets", Type: FilterInt, Op: FilterOpIn, Values: []interface{}{1, 2}}},
+		},
+		{
+			input: `song=in=("a,b","c)d", e)`,
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpIn, Values: []interface{}{"a,b", "c)d", "e"}}},
+		},
+		{
+			input: `song=="a;b";has_link`,
+			want: FilterExpr{
+				{Field: "song", Type: FilterText, Op: FilterOpEq, Values: []interface{}{"a;b"}},
+				{Field: "has_link", Type: FilterBool, Op: FilterOpEq, Values: []interface{}{true}},
+			},
+		},
+		{
+			input: `song==" padded "`,
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpEq, Values: []interface{}{" padded "}}},
+		},
+		{
+			input: `song=="say \"hi\" \\ bye"`,
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpEq, Values: []interface{}{`say "hi" \ bye`}}},
+		},
+		{
+			// Commas and parentheses only end values within lists.
+			input: "song==a,b (c)",
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpEq, Values: []interface{}{"a,b (c)"}}},
+		},
+		{
+			// Unquoted values run up to ";", inner spaces included.
+			input: "song==a has_link",
+			want:  FilterExpr{{Field: "song", Type: FilterText, Op: FilterOpEq, Values: []interface{}{"a has_link"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFilter(tt.input, testFilterSchema)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "title==x", want: `at position 1: unknown field "title"`},
+		{input: "has_link;title==x", want: `at position 10: unknown field "title"`},
+		{input: "==x", want: "at position 1: expected a field name"},
+		{input: "!", want: "at position 2: expected a field name"},
+		{input: "song", want: `at position 5: expected an operator after "song"`},
+		{input: "song?x", want: `at position 5: expected an operator after "song"`},
+		{input: "has_link=~x", want: `at position 11: operator "=~" is not supported by "has_link"`},
+		{input: "song>x", want: `at position 6: operator ">" is not supported by "song"`},
+		{input: "song==", want: "at position 7: expected a value"},
+		{input: "song==;has_link", want: "at position 7: expected a value"},
+		{input: `song=="open`, want: "at position 12: unterminated quoted value"},
+		{input: "song=in=a", want: `at position 9: expected "("`},
+		{input: "song=in=(a,b", want: `at position 13: expected "," or ")"`},
+		{input: "song=in=(a,)", want: "at position 12: expected a value"},
+		{input: `song=in=("a" b)`, want: `at position 14: expected "," or ")"`},
+		{input: `song=="a" has_link`, want: `at position 11: expected ";"`},
+		{input: "couplets==many", want: `at position 15: invalid value "many" of "couplets"`},
+		{input: "release_date<1990-13-01", want: `invalid value "1990-13-01" of "release_date"`},
+		{input: "has_link==maybe", want: `invalid value "maybe" of "has_link"`},
+		{input: "song==" + strings.Repeat("a", maxFilterValueSize+1), want: "is longer than 255 bytes"},
+		{input: strings.Repeat("has_link;", maxFilterPredicates) + "has_link", want: "too many predicates"},
+		{input: "couplets=in=(" + strings.Repeat("1,", maxFilterValues) + "1)", want: "too many values"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseFilter(tt.input, testFilterSchema)
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%q: got %v, want %v", tt.input, err, ErrInvalidFilter)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %q, want it to contain %q", tt.input, err, tt.want)
+		}
+	}
+}
@@ -1,14 +1,21 @@
 package common
 
-import "math"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+)
 
 // swagger:model PaginationMetadata
 type PaginationMetadata struct {
-	CurrentPage  int `json:"current_page"`
-	PageSize     int `json:"page_size"`
-	FirstPage    int `json:"first_page"`
-	LastPage     int `json:"last_page"`
-	TotalRecords int `json:"total_records"`
+	CurrentPage  int    `json:"current_page"`
+	PageSize     int    `json:"page_size"`
+	FirstPage    int    `json:"first_page"`
+	LastPage     int    `json:"last_page"`
+	TotalRecords int    `json:"total_records"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+	PrevCursor   string `json:"prev_cursor,omitempty"`
 }
 
 func CalculateMetadata(totalRecords, page, pageSize int) PaginationMetadata {
@@ -29,3 +36,35 @@ func CalculateMetadata(totalRecords, page, pageSize int) PaginationMetadata {
 		TotalRecords: totalRecords,
 	}
 }
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at a row of an ordered listing by the sort key of that row,
+// so that neighbouring pages are selected with a keyset condition instead of an offset.
+// Clients receive it as an opaque token.
+type Cursor struct {
+	// Sort identifies the ordering the cursor was issued for.
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	// Backward selects the rows before the cursor instead of the ones after it.
+	Backward bool `json:"b,omitempty"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{Sort: "id", Values: []string{"42"}},
+		{Sort: "-release_date,song,id", Values: []string{"1999-12-31", "Teardrop, \"live\"", "7"}, Backward: true},
+		{Sort: "group,id", Values: []string{"", "1"}},
+	}
+
+	for _, cursor := range cursors {
+		decoded, err := DecodeCursor(cursor.Encode())
+		if err != nil {
+			t.Errorf("%+v: %v", cursor, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(*decoded, cursor) {
+			t.Errorf("got %+v, want %+v", *decoded, cursor)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsInvalidTokens(t *testing.T) {
+	tokens := map[string]string{
+		"empty":       "",
+		"not base64":  "!!!",
+		"not json":    "bm90IGpzb24",
+		"no values":   Cursor{Sort: "id"}.Encode(),
+		"wrong types": "eyJzIjoxLCJ2IjoyfQ",
+	}
+
+	for name, token := range tokens {
+		if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidCursor)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/go-playground/validator/v10"
@@ -27,9 +28,11 @@ type BodyResponse struct {
 type ErrorResponse struct {
 	Message string   `json:"message"`
 	Errors  []string `json:"errors,omitempty"`
+	// ID of the request, to look its logs up by
+	RequestID string `json:"request_id,omitempty"`
 }
 
-func FormatErrorResponse(message string, error error) ErrorResponse {
+func FormatErrorResponse(ctx context.Context, message string, error error) ErrorResponse {
 	switch err := error.(type) {
 	case validator.ValidationErrors:
 		errorMessages := make([]string, 0)
@@ -39,13 +42,15 @@ func FormatErrorResponse(message string, error error) ErrorResponse {
 		}
 
 		return ErrorResponse{
-			Message: message,
-			Errors:  errorMessages,
+			Message:   message,
+			Errors:    errorMessages,
+			RequestID: GetRequestID(ctx),
 		}
 	default:
 		return ErrorResponse{
-			Message: message,
-			Errors:  []string{error.Error()},
+			Message:   message,
+			Errors:    []string{error.Error()},
+			RequestID: GetRequestID(ctx),
 		}
 	}
 }
@@ -0,0 +1,22 @@
+package common
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// UserIDHeader identifies the caller on user-scoped endpoints when API keys are not required.
+const UserIDHeader = "X-User-ID"
+
+// Keys of the gin context holding the caller resolved by the auth middleware.
+const (
+	UserIDKey   = "user_id"
+	UserRoleKey = "user_role"
+)
+
+func GetUserID(ctx *gin.Context) string {
+	return ctx.GetString(UserIDKey)
+}
+
+func GetUserRole(ctx *gin.Context) string {
+	return ctx.GetString(UserRoleKey)
+}
@@ -0,0 +1,55 @@
+package health
+
+// swagger:model StatusDTO
+type StatusDTO struct {
+	// ok, degraded when a check that is not critical failed, or unavailable
+	// example: ok
+	Status string `json:"status"`
+}
+
+// swagger:model ReportDTO
+type ReportDTO struct {
+	// ok, degraded when a check that is not critical failed, or unavailable
+	// example: degraded
+	Status string     `json:"status"`
+	Checks []CheckDTO `json:"checks"`
+}
+
+// swagger:model CheckDTO
+type CheckDTO struct {
+	// example: database
+	Name string `json:"name"`
+	// ok or unavailable
+	// example: ok
+	Status string `json:"status"`
+	// Whether the instance is unready while the check fails
+	Critical bool `json:"critical"`
+	// Why the check failed
+	Error string `json:"error,omitempty"`
+	// example: 3
+	DurationMs int64 `json:"duration_ms"`
+}
+
+func toReportDTO(report *Report) ReportDTO {
+	checks := make([]CheckDTO, 0, len(report.Results))
+	for _, result := range report.Results {
+		check := CheckDTO{
+			Name:       result.Check.Name,
+			Status:     StatusOK,
+			Critical:   result.Check.Critical,
+			DurationMs: result.Duration.Milliseconds(),
+		}
+
+		if result.Err != nil {
+			check.Status = StatusUnavailable
+			check.Error = result.Err.Error()
+		}
+
+		checks = append(checks, check)
+	}
+
+	return ReportDTO{
+		Status: report.Status,
+		Checks: checks,
+	}
+}
@@ -0,0 +1,11 @@
+package health
+
+import "errors"
+
+var (
+	ErrWorkerNotStarted  = errors.New("worker has not completed a round yet")
+	ErrWorkerStalled     = errors.New("worker has stalled")
+	ErrPendingMigrations = errors.New("migrations are pending")
+	ErrDirtyMigration    = errors.New("last migration failed, the database is dirty")
+	ErrShuttingDown      = errors.New("instance is shutting down")
+)
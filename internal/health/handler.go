@@ -0,0 +1,86 @@
+package health
+
+import (
+	"effective-mobile/go/config"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	cfg     *config.Config
+	service *HealthService
+}
+
+func NewHealthHandler(cfg *config.Config, service *HealthService) *HealthHandler {
+	return &HealthHandler{
+		cfg:     cfg,
+		service: service,
+	}
+}
+
+// swagger:response StatusResponse
+type statusResponseDescription struct {
+	// in: body
+	Body StatusDTO
+}
+
+// swagger:route GET /livez Health Livez
+// Tell whether the process is alive
+//
+// The process is alive as long as it serves requests, whatever the state of its dependencies.
+//
+// responses:
+//
+//	200: StatusResponse
+func (h *HealthHandler) Livez(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, StatusDTO{Status: StatusOK})
+}
+
+// swagger:route GET /readyz Health Readyz
+// Tell whether the instance is ready to serve
+//
+// The instance is ready while the database is reachable, its schema is up to date and the
+// background workers are healthy, until it starts shutting down. An open circuit breaker of the detail API degrades the
+// instance without making it unready, as every instance shares the API.
+//
+// responses:
+//
+//	200: StatusResponse
+//	503: StatusResponse
+func (h *HealthHandler) Readyz(ctx *gin.Context) {
+	report := h.service.Check(ctx)
+
+	ctx.JSON(statusCode(report), StatusDTO{Status: report.Status})
+}
+
+// swagger:route GET /health Health GetHealthReport
+// Report the outcome of every readiness check
+//
+// Requires the admin role. The status code is the one of GET /readyz.
+//
+// responses:
+//
+//	200: HealthReportResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	503: HealthReportResponse
+func (h *HealthHandler) GetReport(ctx *gin.Context) {
+	report := h.service.Check(ctx)
+
+	// swagger:response HealthReportResponse
+	type responseDescription struct {
+		// in: body
+		Body ReportDTO
+	}
+
+	ctx.JSON(statusCode(report), toReportDTO(report))
+}
+
+func statusCode(report *Report) int {
+	if !report.Ready() {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
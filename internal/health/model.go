@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Statuses of checks and reports.
+const (
+	StatusOK = "ok"
+	// StatusDegraded reports failed checks none of which is critical.
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// CheckFunc checks a dependency or component, returning why it is unhealthy.
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named check of readiness. Only failed critical checks make the instance unready.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      CheckFunc
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Check    *Check
+	Err      error
+	Duration time.Duration
+}
+
+// Report holds the outcome of every check.
+type Report struct {
+	Status  string
+	Results []Result
+}
+
+// Ready tells whether the instance is ready to serve.
+func (r *Report) Ready() bool {
+	return r.Status != StatusUnavailable
+}
+
+// Heartbeat tracks the rounds of a background worker, which is healthy while
+// its rounds keep succeeding.
+type Heartbeat struct {
+	mu  sync.Mutex
+	at  time.Time
+	err error
+}
+
+// Beat records the end of a round with its error.
+func (h *Heartbeat) Beat(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.at = time.Now()
+	h.err = err
+}
+
+// Check fails when the last round failed or no round has ended within staleAfter.
+func (h *Heartbeat) Check(staleAfter time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.at.IsZero() {
+		return ErrWorkerNotStarted
+	}
+
+	if h.err != nil {
+		return fmt.Errorf("last round failed: %w", h.err)
+	}
+
+	if since := time.Since(h.at); since > staleAfter {
+		return fmt.Errorf("%w: last round ended %s ago", ErrWorkerStalled, since.Round(time.Second))
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type HealthRepository struct {
+	config *config.Config
+	db     *pgxpool.Pool
+}
+
+// migrationsTable is where golang-migrate keeps the version of the schema.
+const migrationsTable = "schema_migrations"
+
+func NewHealthRepository(cfg *config.Config, db *pgxpool.Pool) *HealthRepository {
+	return &HealthRepository{
+		config: cfg,
+		db:     db,
+	}
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	return r.db.Ping(ctx)
+}
+
+// GetMigrationVersion returns the version of the schema, zero when no migrations are applied,
+// and whether the last migration failed.
+func (r *HealthRepository) GetMigrationVersion(ctx context.Context) (uint, bool, error) {
+	query := fmt.Sprintf(`SELECT version, dirty FROM %s LIMIT 1`, migrationsTable)
+
+	var version int64
+	var dirty bool
+	err := r.db.QueryRow(ctx, query).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/migrations"
+	"effective-mobile/go/pkg/database"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthService checks whether the instance is ready to serve.
+type HealthService struct {
+	config *config.Config
+	repo   *HealthRepository
+	checks []*Check
+	// draining is set once shutdown begins.
+	draining atomic.Bool
+}
+
+func NewHealthService(cfg *config.Config, repo *HealthRepository) *HealthService {
+	s := &HealthService{
+		config: cfg,
+		repo:   repo,
+	}
+
+	s.AddCheck("shutdown", true, s.checkShutdown)
+	s.AddCheck("database", true, repo.Ping)
+	s.AddCheck("migrations", true, s.checkMigrations)
+
+	return s
+}
+
+// AddCheck registers a check of readiness. Checks are to be registered before
+// the service is used.
+func (s *HealthService) AddCheck(name string, critical bool, run CheckFunc) {
+	s.checks = append(s.checks, &Check{Name: name, Critical: critical, Run: run})
+}
+
+// SetDraining makes the instance unready for the rest of its life, so that it is no longer
+// sent requests while it shuts down.
+func (s *HealthService) SetDraining() {
+	s.draining.Store(true)
+}
+
+// Check runs every check concurrently, each limited to Health.CheckTimeout.
+func (s *HealthService) Check(ctx context.Context) *Report {
+	report := &Report{
+		Status:  StatusOK,
+		Results: make([]Result, len(s.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Results[i] = s.run(ctx, check)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, result := range report.Results {
+		switch {
+		case result.Err == nil:
+		case result.Check.Critical:
+			report.Status = StatusUnavailable
+		case report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// run runs the check, giving up on it once it has timed out even if it ignores its context.
+func (s *HealthService) run(ctx context.Context, check *Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Health.CheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", s.config.Health.CheckTimeout)
+	}
+
+	return Result{
+		Check:    check,
+		Err:      err,
+		Duration: time.Since(start),
+	}
+}
+
+func (s *HealthService) checkShutdown(ctx context.Context) error {
+	if s.draining.Load() {
+		return ErrShuttingDown
+	}
+
+	return nil
+}
+
+// checkMigrations fails while migrations of this build are pending or the last one failed.
+// A schema newer than the build is accepted, as it is while a newer build rolls out.
+func (s *HealthService) checkMigrations(ctx context.Context) error {
+	latest, err := database.LatestVersion(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := s.repo.GetMigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("%w at version %d", ErrDirtyMigration, version)
+	}
+
+	if version < latest {
+		return fmt.Errorf("%w: at version %d of %d", ErrPendingMigrations, version, latest)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package lifecycle
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// StartFunc starts a component, returning once it is running.
+type StartFunc func(ctx context.Context) error
+
+// StopFunc stops a component, giving up once the context is done.
+type StopFunc func(ctx context.Context) error
+
+type component struct {
+	name  string
+	start StartFunc
+	stop  StopFunc
+}
+
+// Manager starts the components of the application in the order they were added, which is
+// to be the order of their dependencies, and stops them in the reverse order on SIGINT
+// or SIGTERM, or once a component has failed.
+type Manager struct {
+	config     *config.Config
+	components []*component
+	onDrain    []func()
+
+	failOnce sync.Once
+	failed   chan error
+}
+
+func NewManager(cfg *config.Config) *Manager {
+	return &Manager{
+		config: cfg,
+		failed: make(chan error, 1),
+	}
+}
+
+// Add registers a component after the components it depends on. Either function may be nil,
+// e.g. for components that are running once created.
+func (m *Manager) Add(name string, start StartFunc, stop StopFunc) {
+	m.components = append(m.components, &component{name: name, start: start, stop: stop})
+}
+
+// Go registers a background worker, which runs until its context is cancelled.
+// It is stopped by cancelling the context and waiting for run to return.
+func (m *Manager) Go(name string, run func(ctx context.Context)) {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+
+	m.Add(name, func(ctx context.Context) error {
+		// The worker outlives the context it is started with.
+		ctx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+		go func() {
+			defer close(done)
+			run(ctx)
+		}()
+
+		return nil
+	}, func(ctx context.Context) error {
+		cancel()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("worker did not stop: %w", ctx.Err())
+		}
+	})
+}
+
+// OnDrain registers a function called once shutdown begins, before any component is stopped.
+func (m *Manager) OnDrain(f func()) {
+	m.onDrain = append(m.onDrain, f)
+}
+
+// Fail shuts the application down because a component has failed. Only the first failure is kept.
+func (m *Manager) Fail(err error) {
+	m.failOnce.Do(func() {
+		m.failed <- err
+	})
+}
+
+// Run starts every component and blocks until a signal is received or a component fails,
+// then drains and stops the components within Shutdown.DrainTimeout. A second signal
+// cuts the shutdown short. The error is set when starting failed, a component failed
+// or a component could not be stopped in time.
+func (m *Manager) Run(ctx context.Context) error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	started, err := m.start(ctx)
+	if err == nil {
+		select {
+		case s := <-signals:
+			log.Info("signal received, shutting down: ", s)
+		case err = <-m.failed:
+			log.Error("component failed, shutting down: ", err)
+		}
+	}
+
+	for _, f := range m.onDrain {
+		f()
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.config.Shutdown.DrainTimeout)
+	defer cancel()
+
+	go func() {
+		select {
+		case s := <-signals:
+			log.Warn("signal received again, cutting shutdown short: ", s)
+			cancel()
+		case <-stopCtx.Done():
+		}
+	}()
+
+	// Load balancers are given time to notice the instance is no longer ready.
+	if err == nil && m.config.Shutdown.ReadinessDelay > 0 {
+		select {
+		case <-time.After(m.config.Shutdown.ReadinessDelay):
+		case <-stopCtx.Done():
+		}
+	}
+
+	return errors.Join(err, m.stop(stopCtx, started))
+}
+
+// Abort stops the components that run once created, i.e. were added without a start function,
+// in the reverse order. It takes the place of Run when setting the application up fails midway.
+func (m *Manager) Abort() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.Shutdown.DrainTimeout)
+	defer cancel()
+
+	var running []*component
+	for _, c := range m.components {
+		if c.start == nil {
+			running = append(running, c)
+		}
+	}
+
+	return m.stop(ctx, running)
+}
+
+// start starts the components in order, returning those started.
+func (m *Manager) start(ctx context.Context) ([]*component, error) {
+	for i, c := range m.components {
+		if c.start != nil {
+			if err := c.start(ctx); err != nil {
+				return m.components[:i], fmt.Errorf("failed to start %s: %w", c.name, err)
+			}
+		}
+
+		log.WithField("component", c.name).Debug("component started")
+	}
+
+	return m.components, nil
+}
+
+// stop stops the components in the reverse order.
+func (m *Manager) stop(ctx context.Context, components []*component) error {
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		if c.stop == nil {
+			continue
+		}
+
+		if err := c.stop(ctx); err != nil {
+			log.WithField("component", c.name).Error("failed to stop component: ", err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", c.name, err))
+			continue
+		}
+
+		log.WithField("component", c.name).Debug("component stopped")
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Setup makes the standard logger write JSON at the configured level. Without a level
+// everything down to debug is logged in development and down to info otherwise.
+func Setup(cfg *config.Config) error {
+	log.SetFormatter(&log.JSONFormatter{})
+
+	level := log.InfoLevel
+	if cfg.Mode == "development" {
+		level = log.DebugLevel
+	}
+
+	if cfg.Log.Level != "" {
+		var err error
+		level, err = log.ParseLevel(cfg.Log.Level)
+		if err != nil {
+			return fmt.Errorf("invalid log level: %w", err)
+		}
+	}
+
+	log.SetLevel(level)
+	return nil
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of the context carrying the logger.
+func WithLogger(ctx context.Context, logger *log.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of the context, which carries the fields of the request
+// the context belongs to, or a logger without fields.
+func FromContext(ctx context.Context) *log.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*log.Entry); ok {
+		return logger
+	}
+
+	return log.NewEntry(log.StandardLogger())
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"crypto/rand"
+	"effective-mobile/go/internal/common"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxRequestIDLength limits the request IDs accepted from callers.
+const maxRequestIDLength = 128
+
+// Middleware correlates the logs of a request. It takes the request ID from the X-Request-ID
+// header, generating one when the caller sent none, echoes it in the response and puts
+// a logger carrying it into the context of the request. Once the request is handled,
+// it is logged in place of the access log of gin.
+func Middleware(ctx *gin.Context) {
+	start := time.Now()
+
+	id := ctx.GetHeader(common.RequestIDHeader)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+
+	ctx.Header(common.RequestIDHeader, id)
+
+	fields := log.Fields{
+		"request_id": id,
+		"method":     ctx.Request.Method,
+		"route":      ctx.FullPath(),
+	}
+
+	if span := trace.SpanContextFromContext(ctx.Request.Context()); span.IsValid() {
+		fields["trace_id"] = span.TraceID().String()
+	}
+
+	reqCtx := common.WithRequestID(ctx.Request.Context(), id)
+	reqCtx = WithLogger(reqCtx, log.WithFields(fields))
+	ctx.Request = ctx.Request.WithContext(reqCtx)
+
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	entry := FromContext(ctx).WithFields(log.Fields{
+		"path":       ctx.Request.URL.Path,
+		"status":     status,
+		"latency_ms": time.Since(start).Milliseconds(),
+		"client_ip":  ctx.ClientIP(),
+		"bytes":      ctx.Writer.Size(),
+	})
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		entry.Error("request handled")
+	case status >= http.StatusBadRequest:
+		entry.Warn("request handled")
+	default:
+		entry.Info("request handled")
+	}
+}
+
+// AddFields adds the fields to the logger of the request.
+func AddFields(ctx *gin.Context, fields log.Fields) {
+	ctx.Request = ctx.Request.WithContext(WithLogger(ctx.Request.Context(), FromContext(ctx).WithFields(fields)))
+}
+
+// Recovery turns a panic of a handler into an internal server error, logging it with its stack.
+func Recovery(ctx *gin.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			FromContext(ctx).WithFields(log.Fields{
+				"panic": r,
+				"stack": string(debug.Stack()),
+			}).Error("handler panicked")
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "internal server error", errors.New("unexpected error")))
+		}
+	}()
+
+	ctx.Next()
+}
+
+// validRequestID tells whether a request ID sent by a caller is safe to log and echo.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	// crypto/rand does not fail on the supported platforms.
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
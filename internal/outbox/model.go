@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Event is a change recorded in the outbox.
+type Event struct {
+	// Seq orders the events, it is assigned when the event is written.
+	Seq int64 `db:"seq"`
+	// ID identifies the event across publications, so that consumers can drop duplicates.
+	ID   string `db:"event_id"`
+	Type string `db:"event_type"`
+	// Key identifies the entity the event is about; brokers may partition events by it.
+	Key        string          `db:"event_key"`
+	Payload    json.RawMessage `db:"payload"`
+	OccurredAt time.Time       `db:"occurred_at"`
+}
+
+// NewEvent returns an event with a new ID and the payload encoded as JSON.
+func NewEvent(eventType, key string, payload interface{}) (*Event, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		ID:         hex.EncodeToString(id),
+		Type:       eventType,
+		Key:        key,
+		Payload:    data,
+		OccurredAt: time.Now().UTC(),
+	}, nil
+}
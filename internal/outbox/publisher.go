@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"context"
+	"effective-mobile/go/internal/logging"
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EventPublisher hands outbox events over to their consumers, e.g. a message broker.
+// An event stays in the outbox until every publisher has published it, so an event
+// may be published again after a failure and consumers are to drop duplicates by Event.ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+const (
+	PublisherLog     = "log"
+	PublisherChannel = "channel"
+)
+
+// channelBuffer is the number of events buffered for every channel subscriber.
+const channelBuffer = 64
+
+// NewPublisher returns the publisher of the given name, see OUTBOX_PUBLISHERS.
+func NewPublisher(name string) (EventPublisher, error) {
+	switch name {
+	case PublisherLog:
+		return NewLogPublisher(), nil
+	case PublisherChannel:
+		return NewChannelPublisher(channelBuffer), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", name)
+	}
+}
+
+// LogPublisher writes events to the log.
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event *Event) error {
+	logging.FromContext(ctx).WithFields(log.Fields{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+		"event_key":  event.Key,
+		"payload":    string(event.Payload),
+	}).Info("event published")
+
+	return nil
+}
+
+// ChannelPublisher passes events to subscribers within the process. The server subscribes
+// nothing to it by itself; it is the extension point for consumers running in the process,
+// which subscribe to the publisher registered with Relay.AddPublisher before the relay runs.
+type ChannelPublisher struct {
+	buffer      int
+	mu          sync.Mutex
+	subscribers map[chan *Event]struct{}
+}
+
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{
+		buffer:      buffer,
+		subscribers: make(map[chan *Event]struct{}),
+	}
+}
+
+// Subscribe returns a channel of the events published from now on and a function
+// that cancels the subscription and closes the channel. The channel is also closed when
+// the subscriber falls behind, see Publish.
+func (p *ChannelPublisher) Subscribe() (<-chan *Event, func()) {
+	ch := make(chan *Event, p.buffer)
+
+	p.mu.Lock()
+	p.subscribers[ch] = struct{}{}
+	p.mu.Unlock()
+
+	return ch, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.unsubscribe(ch)
+	}
+}
+
+// unsubscribe ends the subscription, the lock must be held.
+func (p *ChannelPublisher) unsubscribe(ch chan *Event) {
+	if _, ok := p.subscribers[ch]; !ok {
+		return
+	}
+
+	delete(p.subscribers, ch)
+	close(ch)
+}
+
+// Close ends every subscription. Events are not to be published once it is closed.
+func (p *ChannelPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ch := range p.subscribers {
+		p.unsubscribe(ch)
+	}
+
+	return nil
+}
+
+// Publish passes the event to every subscriber without waiting for any of them.
+// Subscribers whose buffer is full are dropped and their channel closed rather than
+// holding the relay up; they are to subscribe again and tolerate the missed events.
+func (p *ChannelPublisher) Publish(ctx context.Context, event *Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ch := range p.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logging.FromContext(ctx).Warn("channel subscriber fell behind, dropping it")
+			p.unsubscribe(ch)
+		}
+	}
+
+	return nil
+}
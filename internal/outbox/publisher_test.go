@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testEvents(n int) []*Event {
+	events := make([]*Event, 0, n)
+	for i := 1; i <= n; i++ {
+		events = append(events, &Event{Seq: int64(i), ID: strconv.Itoa(i)})
+	}
+
+	return events
+}
+
+// receive reads the channel until it is closed, failing the test if that takes too long.
+func receive(t *testing.T, ch <-chan *Event) []*Event {
+	t.Helper()
+
+	var events []*Event
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return events
+			}
+
+			events = append(events, event)
+		case <-timeout:
+			t.Fatal("channel was not closed")
+		}
+	}
+}
+
+func TestChannelPublisherPublishesInOrder(t *testing.T) {
+	p := NewChannelPublisher(10)
+	first, cancelFirst := p.Subscribe()
+	second, cancelSecond := p.Subscribe()
+
+	events := testEvents(5)
+	for _, event := range events {
+		if err := p.Publish(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancelFirst()
+	cancelSecond()
+
+	for name, ch := range map[string]<-chan *Event{"first": first, "second": second} {
+		got := receive(t, ch)
+		if len(got) != len(events) {
+			t.Fatalf("%s subscriber received %d events, want %d", name, len(got), len(events))
+		}
+
+		for i := range got {
+			if got[i] != events[i] {
+				t.Errorf("%s subscriber received event %s at %d, want %s", name, got[i].ID, i, events[i].ID)
+			}
+		}
+	}
+}
+
+func TestChannelPublisherDropsSlowSubscribers(t *testing.T) {
+	p := NewChannelPublisher(2)
+	slow, cancelSlow := p.Subscribe()
+	fast, cancelFast := p.Subscribe()
+
+	var received []*Event
+	for _, event := range testEvents(5) {
+		if err := p.Publish(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+
+		received = append(received, <-fast)
+	}
+
+	got := receive(t, slow)
+	if len(got) != 2 || got[0].ID != "1" || got[1].ID != "2" {
+		t.Errorf("slow subscriber received %v, want the events fitting its buffer", got)
+	}
+
+	if len(received) != 5 {
+		t.Errorf("fast subscriber received %d events, want 5", len(received))
+	}
+
+	// Cancelling a dropped subscription does nothing.
+	cancelSlow()
+	cancelFast()
+
+	if _, ok := <-fast; ok {
+		t.Error("fast subscriber is still open")
+	}
+}
+
+func TestChannelPublisherSlowSubscriberUnsubscribes(t *testing.T) {
+	p := NewChannelPublisher(1)
+	_, cancel := p.Subscribe()
+
+	ctx, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+
+	// Publishing must neither wait for the subscriber nor keep it from unsubscribing.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for _, event := range testEvents(100) {
+			if err := p.Publish(ctx, event); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("publishing is held up by a subscriber that unsubscribed")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.subscribers) != 0 {
+		t.Errorf("%d subscribers left, want none", len(p.subscribers))
+	}
+}
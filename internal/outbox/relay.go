@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/health"
+	"effective-mobile/go/internal/logging"
+	"fmt"
+	"time"
+)
+
+const (
+	// relayBatchSize limits the events drained at once.
+	relayBatchSize = 100
+	// cleanupInterval is how often published events past the retention are deleted.
+	cleanupInterval = time.Minute
+)
+
+// Relay publishes the events written to the outbox with every registered publisher.
+// Events of the same key are published in the order they were written, see OutboxRepository.Drain.
+type Relay struct {
+	config     *config.Config
+	repo       *OutboxRepository
+	publishers []EventPublisher
+	heartbeat  health.Heartbeat
+}
+
+func NewRelay(cfg *config.Config, repo *OutboxRepository) *Relay {
+	return &Relay{
+		config: cfg,
+		repo:   repo,
+	}
+}
+
+// AddPublisher registers a publisher of the events. Publishers are to be registered
+// before the relay is run.
+func (r *Relay) AddPublisher(publisher EventPublisher) {
+	r.publishers = append(r.publishers, publisher)
+}
+
+// Run drains the outbox every Outbox.PollInterval until the context is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).WithField("worker", "outbox_relay"))
+
+	ticker := time.NewTicker(r.config.Outbox.PollInterval)
+	defer ticker.Stop()
+
+	var cleanedAt time.Time
+	for {
+		r.drain(ctx)
+
+		if time.Since(cleanedAt) >= cleanupInterval {
+			r.cleanup(ctx)
+			cleanedAt = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain publishes events batch by batch until none are left or another relay is draining.
+// A failed publication stops the drain, so that the event is retried before any later one is published.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		drained, err := r.repo.Drain(ctx, relayBatchSize, func(events []*Event) (int, error) {
+			return r.publish(ctx, events)
+		})
+		r.heartbeat.Beat(err)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to relay outbox events: ", err)
+			return
+		}
+
+		if drained < relayBatchSize {
+			return
+		}
+	}
+}
+
+// Check fails while the relay keeps failing to publish events or has stalled.
+func (r *Relay) Check(ctx context.Context) error {
+	return r.heartbeat.Check(r.config.Health.WorkerStaleAfter)
+}
+
+// publish publishes the events in order, returning how many have been published by every publisher.
+func (r *Relay) publish(ctx context.Context, events []*Event) (int, error) {
+	for i, event := range events {
+		for _, publisher := range r.publishers {
+			if err := publisher.Publish(ctx, event); err != nil {
+				return i, fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+			}
+		}
+	}
+
+	return len(events), nil
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.repo.DeletePublished(ctx, time.Now().Add(-r.config.Outbox.Retention))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to delete published outbox events: ", err)
+		return
+	}
+
+	if deleted > 0 {
+		logging.FromContext(ctx).Debug("published outbox events deleted: ", deleted)
+	}
+}
@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/logging"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type OutboxRepository struct {
+	config *config.Config
+	db     *pgxpool.Pool
+}
+
+const outboxTable = "outbox_events"
+
+// relayLockKey names the advisory lock held by the relay draining the outbox.
+const relayLockKey = "outbox_relay"
+
+func NewOutboxRepository(cfg *config.Config, db *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{
+		config: cfg,
+		db:     db,
+	}
+}
+
+// Drain passes up to limit unpublished events in seq order to publish, which returns how many
+// of them, from the first one on, it has published. Those are marked as published when the
+// transaction commits.
+//
+// The transaction holds an advisory lock, so that a single relay drains the outbox at a time
+// while the relays of other instances find nothing to drain. Seqs are assigned on write but
+// become visible on commit, so an event may be drained after a later one of another key.
+// Events of the same key are written while the entity is locked and are drained in order.
+func (r *OutboxRepository) Drain(ctx context.Context, limit int, publish func(events []*Event) (int, error)) (int, error) {
+	lock := `SELECT pg_try_advisory_xact_lock(hashtext($1))`
+
+	query := fmt.Sprintf(`
+		SELECT seq, event_id, event_type, event_key, payload, occurred_at
+		FROM %s
+		WHERE published_at IS NULL
+		ORDER BY seq
+		LIMIT $1
+	`, outboxTable)
+
+	update := fmt.Sprintf(`UPDATE %s SET published_at = NOW() WHERE seq = ANY($1)`, outboxTable)
+
+	drained := 0
+	var publishErr error
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, lock, relayLockKey).Scan(&locked); err != nil {
+			return err
+		}
+
+		if !locked {
+			return nil
+		}
+
+		rows, err := tx.Query(ctx, query, limit)
+		if err != nil {
+			return err
+		}
+
+		var events []*Event
+		for rows.Next() {
+			var event Event
+			err := rows.Scan(&event.Seq, &event.ID, &event.Type, &event.Key, &event.Payload, &event.OccurredAt)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+
+			events = append(events, &event)
+		}
+
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		drained, publishErr = publish(events)
+
+		seqs := make([]int64, 0, drained)
+		for _, event := range events[:drained] {
+			seqs = append(seqs, event.Seq)
+		}
+
+		_, err = tx.Exec(ctx, update, seqs)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if drained > 0 {
+		logging.FromContext(ctx).Debug("outbox events published: ", drained)
+	}
+
+	return drained, publishErr
+}
+
+// DeletePublished deletes events published before the given time.
+func (r *OutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE published_at < $1`, outboxTable)
+
+	tag, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Write stores the events within the transaction of the change they describe.
+func Write(ctx context.Context, tx pgx.Tx, events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	_, err := tx.CopyFrom(ctx,
+		pgx.Identifier{outboxTable},
+		[]string{"event_id", "event_type", "event_key", "payload", "occurred_at"},
+		pgx.CopyFromSlice(len(events), func(i int) ([]interface{}, error) {
+			event := events[i]
+			return []interface{}{event.ID, event.Type, event.Key, event.Payload, event.OccurredAt}, nil
+		}),
+	)
+
+	return err
+}
@@ -0,0 +1,60 @@
+package playlist
+
+import (
+	"effective-mobile/go/internal/song"
+	"time"
+)
+
+type UpdatePlaylistDTO struct {
+	PlaylistID  int
+	Title       *string
+	Description *string
+	Visibility  *string
+}
+
+// swagger:model PlaylistDTO
+type PlaylistDTO struct {
+	ID          int       `json:"id"`
+	Owner       string    `json:"owner"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// swagger:model PlaylistItemDTO
+type PlaylistItemDTO struct {
+	ItemID   int       `json:"item_id"`
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+	song.SongDTO
+}
+
+func (p *PlaylistModel) ToDTO() PlaylistDTO {
+	return PlaylistDTO{
+		ID:          p.ID,
+		Owner:       p.Owner,
+		Title:       p.Title,
+		Description: p.Description,
+		Visibility:  p.Visibility,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func (i *PlaylistItemModel) ToDTO() PlaylistItemDTO {
+	dto := PlaylistItemDTO{
+		ItemID:   i.ID,
+		Position: i.Position,
+		AddedAt:  i.AddedAt,
+	}
+
+	if i.Song != nil {
+		dto.SongDTO = i.Song.ToDTO()
+	} else {
+		dto.SongDTO.ID = i.SongID
+	}
+
+	return dto
+}
@@ -0,0 +1,11 @@
+package playlist
+
+import "errors"
+
+var (
+	ErrPlaylistNotFound = errors.New("playlist not found")
+	ErrItemNotFound     = errors.New("playlist item not found")
+	ErrSongNotFound     = errors.New("song not found")
+	ErrForbidden        = errors.New("access to the playlist is forbidden")
+	ErrUnauthorized     = errors.New("user id is required")
+)
@@ -0,0 +1,505 @@
+package playlist
+
+import (
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PlaylistHandler struct {
+	cfg     *config.Config
+	service *PlaylistService
+}
+
+func NewPlaylistHandler(cfg *config.Config, service *PlaylistService) *PlaylistHandler {
+	return &PlaylistHandler{
+		cfg:     cfg,
+		service: service,
+	}
+}
+
+// swagger:route POST /playlists Playlists CreatePlaylist
+// Create a new playlist owned by the calling user
+//
+// responses:
+//
+//	201: PlaylistResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) CreatePlaylist(ctx *gin.Context) {
+	// swagger:parameters CreatePlaylist
+	type requestDescription struct {
+		// ID of the calling user
+		// in: header
+		// required: true
+		UserID string `json:"X-User-ID"`
+		// in: body
+		Body struct {
+			// Title of the playlist
+			// required: true
+			// example: Trip-hop classics
+			Title string `json:"title" binding:"required,max=255"`
+			// Description of the playlist
+			// required: false
+			Description string `json:"description"`
+			// Visibility of the playlist
+			// required: false
+			// enum: public,unlisted,private
+			// default: private
+			Visibility string `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	playlist := &PlaylistModel{
+		Owner:       common.GetUserID(ctx),
+		Title:       req.Body.Title,
+		Description: req.Body.Description,
+		Visibility:  req.Body.Visibility,
+	}
+
+	if err := h.service.CreatePlaylist(ctx, playlist); err != nil {
+		h.handleError(ctx, "failed to create playlist", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, common.BodyResponse{
+		Message: "playlist successfully created",
+		Body:    playlist.ToDTO(),
+	})
+}
+
+// swagger:route GET /playlists Playlists GetPlaylists
+// Get list of public playlists and playlists of the calling user
+//
+// responses:
+//
+//	200: PlaylistsResponse
+//	400: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) GetPlaylists(ctx *gin.Context) {
+	// swagger:parameters GetPlaylists
+	type requestDescription struct {
+		// Page number
+		// in: query
+		// required: false
+		// default: 1
+		Page int `form:"page,default=1" json:"page" binding:"min=1"`
+		// Number of playlists per page
+		// in: query
+		// required: false
+		// default: 10
+		Limit int `form:"limit,default=10" json:"limit" binding:"min=1,max=50"`
+		// Owner of the playlists
+		// in: query
+		// required: false
+		Owner *string `form:"owner" json:"owner"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	playlists, metadata, err := h.service.GetPlaylists(ctx, PlaylistFilter{Owner: req.Owner}, common.GetUserID(ctx), req.Page, req.Limit)
+	if err != nil {
+		h.handleError(ctx, "failed to get playlists", err)
+		return
+	}
+
+	playlistsDTO := make([]PlaylistDTO, 0, len(playlists))
+	for _, playlist := range playlists {
+		playlistsDTO = append(playlistsDTO, playlist.ToDTO())
+	}
+
+	// swagger:response PlaylistsResponse
+	type responseDescription struct {
+		// in: body
+		Body common.PaginationResponse[PlaylistDTO]
+	}
+
+	ctx.JSON(http.StatusOK, responseDescription{
+		Body: common.PaginationResponse[PlaylistDTO]{
+			Message:            "playlists successfully retrieved",
+			PaginationMetadata: *metadata,
+			Body:               playlistsDTO,
+		},
+	}.Body)
+}
+
+// swagger:route GET /playlists/:id Playlists GetPlaylist
+// Get a playlist by its ID
+//
+// responses:
+//
+//	200: PlaylistResponse
+//	400: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) GetPlaylist(ctx *gin.Context) {
+	// swagger:parameters GetPlaylist
+	type requestDescription struct {
+		// ID of the playlist
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid playlist id", err))
+		return
+	}
+
+	playlist, err := h.service.GetPlaylist(ctx, req.ID, common.GetUserID(ctx))
+	if err != nil {
+		h.handleError(ctx, "failed to get playlist", err)
+		return
+	}
+
+	// swagger:response PlaylistResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string      `json:"message"`
+			Body    PlaylistDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "playlist successfully retrieved",
+		Body:    playlist.ToDTO(),
+	})
+}
+
+// swagger:route PATCH /playlists/:id Playlists UpdatePlaylist
+// Update a playlist owned by the calling user
+//
+// responses:
+//
+//	200: Response
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) UpdatePlaylist(ctx *gin.Context) {
+	// swagger:parameters UpdatePlaylist
+	type requestDescription struct {
+		// ID of the playlist
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// in: body
+		Body struct {
+			// Title of the playlist
+			// required: false
+			Title *string `json:"title" binding:"omitempty,min=1,max=255"`
+			// Description of the playlist
+			// required: false
+			Description *string `json:"description"`
+			// Visibility of the playlist
+			// required: false
+			// enum: public,unlisted,private
+			Visibility *string `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid playlist id", err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	if err := h.service.UpdatePlaylist(ctx, UpdatePlaylistDTO{
+		PlaylistID:  req.ID,
+		Title:       req.Body.Title,
+		Description: req.Body.Description,
+		Visibility:  req.Body.Visibility,
+	}, common.GetUserID(ctx)); err != nil {
+		h.handleError(ctx, "failed to update playlist", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.Response{Message: "playlist successfully updated"})
+}
+
+// swagger:route DELETE /playlists/:id Playlists DeletePlaylist
+// Delete a playlist owned by the calling user
+//
+// responses:
+//
+//	200: Response
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) DeletePlaylist(ctx *gin.Context) {
+	// swagger:parameters DeletePlaylist
+	type requestDescription struct {
+		// ID of the playlist
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid playlist id", err))
+		return
+	}
+
+	if err := h.service.DeletePlaylist(ctx, req.ID, common.GetUserID(ctx)); err != nil {
+		h.handleError(ctx, "failed to delete playlist", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.Response{Message: "playlist successfully deleted"})
+}
+
+// swagger:route GET /playlists/:id/songs Playlists GetPlaylistSongs
+// Get songs of a playlist in playlist order
+//
+// responses:
+//
+//	200: PlaylistSongsResponse
+//	400: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) GetPlaylistSongs(ctx *gin.Context) {
+	// swagger:parameters GetPlaylistSongs
+	type requestDescription struct {
+		// ID of the playlist
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// Page number
+		// in: query
+		// required: false
+		// default: 1
+		Page int `form:"page,default=1" json:"page" binding:"min=1"`
+		// Number of songs per page
+		// in: query
+		// required: false
+		// default: 10
+		Limit int `form:"limit,default=10" json:"limit" binding:"min=1,max=50"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid playlist id", err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	items, metadata, err := h.service.GetPlaylistSongs(ctx, req.ID, common.GetUserID(ctx), req.Page, req.Limit)
+	if err != nil {
+		h.handleError(ctx, "failed to get playlist songs", err)
+		return
+	}
+
+	itemsDTO := make([]PlaylistItemDTO, 0, len(items))
+	for _, item := range items {
+		itemsDTO = append(itemsDTO, item.ToDTO())
+	}
+
+	// swagger:response PlaylistSongsResponse
+	type responseDescription struct {
+		// in: body
+		Body common.PaginationResponse[PlaylistItemDTO]
+	}
+
+	ctx.JSON(http.StatusOK, responseDescription{
+		Body: common.PaginationResponse[PlaylistItemDTO]{
+			Message:            "playlist songs successfully retrieved",
+			PaginationMetadata: *metadata,
+			Body:               itemsDTO,
+		},
+	}.Body)
+}
+
+// swagger:route POST /playlists/:id/items Playlists AddPlaylistItem
+// Add a song to a playlist, optionally at the given position
+//
+// responses:
+//
+//	201: PlaylistItemResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) AddItem(ctx *gin.Context) {
+	// swagger:parameters AddPlaylistItem
+	type requestDescription struct {
+		// ID of the playlist
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// in: body
+		Body struct {
+			// ID of the song
+			// required: true
+			SongID int `json:"song_id" binding:"required"`
+			// Zero-based position of the song, appended to the end when omitted
+			// required: false
+			Position *int `json:"position" binding:"omitempty,min=0"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid playlist id", err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	item, err := h.service.AddItem(ctx, req.ID, req.Body.SongID, req.Body.Position, common.GetUserID(ctx))
+	if err != nil {
+		h.handleError(ctx, "failed to add song to playlist", err)
+		return
+	}
+
+	// swagger:response PlaylistItemResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string          `json:"message"`
+			Body    PlaylistItemDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, common.BodyResponse{
+		Message: "song successfully added to playlist",
+		Body:    item.ToDTO(),
+	})
+}
+
+// swagger:route DELETE /playlists/:id/items/:item_id Playlists RemovePlaylistItem
+// Remove an item from a playlist
+//
+// responses:
+//
+//	200: Response
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) RemoveItem(ctx *gin.Context) {
+	// swagger:parameters RemovePlaylistItem
+	type requestDescription struct {
+		// ID of the playlist
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// ID of the playlist item
+		// in: path
+		// required: true
+		ItemID int `uri:"item_id" binding:"required" json:"item_id"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid playlist item id", err))
+		return
+	}
+
+	if err := h.service.RemoveItem(ctx, req.ID, req.ItemID, common.GetUserID(ctx)); err != nil {
+		h.handleError(ctx, "failed to remove song from playlist", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.Response{Message: "song successfully removed from playlist"})
+}
+
+// swagger:route POST /playlists/:id/items/:item_id/move Playlists MovePlaylistItem
+// Move an item of a playlist to another position
+//
+// responses:
+//
+//	200: PlaylistItemResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *PlaylistHandler) MoveItem(ctx *gin.Context) {
+	// swagger:parameters MovePlaylistItem
+	type requestDescription struct {
+		// ID of the playlist
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// ID of the playlist item
+		// in: path
+		// required: true
+		ItemID int `uri:"item_id" binding:"required" json:"item_id"`
+		// in: body
+		Body struct {
+			// New zero-based position of the item
+			// required: true
+			Position *int `json:"position" binding:"required,min=0"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid playlist item id", err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	item, err := h.service.MoveItem(ctx, req.ID, req.ItemID, *req.Body.Position, common.GetUserID(ctx))
+	if err != nil {
+		h.handleError(ctx, "failed to move playlist item", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "playlist item successfully moved",
+		Body:    item.ToDTO(),
+	})
+}
+
+func (h *PlaylistHandler) handleError(ctx *gin.Context, message string, err error) {
+	switch err {
+	case ErrUnauthorized:
+		ctx.JSON(http.StatusUnauthorized, common.FormatErrorResponse(ctx, message, err))
+	case ErrForbidden:
+		ctx.JSON(http.StatusForbidden, common.FormatErrorResponse(ctx, message, err))
+	case ErrPlaylistNotFound, ErrItemNotFound, ErrSongNotFound:
+		ctx.JSON(http.StatusNotFound, common.FormatErrorResponse(ctx, message, err))
+	default:
+		logging.FromContext(ctx).Error(message, ": ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, message, err))
+	}
+}
@@ -0,0 +1,45 @@
+package playlist
+
+import (
+	"effective-mobile/go/internal/song"
+	"time"
+)
+
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+type PlaylistModel struct {
+	ID          int       `db:"id"`
+	Owner       string    `db:"owner"`
+	Title       string    `db:"title"`
+	Description string    `db:"description"`
+	Visibility  string    `db:"visibility"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type PlaylistItemModel struct {
+	ID         int       `db:"id"`
+	PlaylistID int       `db:"playlist_id"`
+	SongID     int       `db:"song_id"`
+	Position   int       `db:"position"`
+	AddedAt    time.Time `db:"added_at"`
+	Song       *song.SongModel
+}
+
+type PlaylistFilter struct {
+	Owner *string
+}
+
+// CanView reports whether the user may read the playlist and its items.
+func (p *PlaylistModel) CanView(userID string) bool {
+	return p.Visibility != VisibilityPrivate || p.Owner == userID
+}
+
+// CanEdit reports whether the user may change the playlist and its items.
+func (p *PlaylistModel) CanEdit(userID string) bool {
+	return userID != "" && p.Owner == userID
+}
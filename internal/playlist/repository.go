@@ -0,0 +1,427 @@
+package playlist
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/song"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type PlaylistRepository struct {
+	config *config.Config
+	db     *pgxpool.Pool
+}
+
+const (
+	playlistsTable     = "playlists"
+	playlistItemsTable = "playlist_items"
+	songsTable         = "songs"
+)
+
+func NewPlaylistRepository(cfg *config.Config, db *pgxpool.Pool) *PlaylistRepository {
+	return &PlaylistRepository{
+		config: cfg,
+		db:     db,
+	}
+}
+
+func (r *PlaylistRepository) CreatePlaylist(ctx context.Context, playlist *PlaylistModel) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (owner, title, description, visibility)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, playlistsTable)
+
+	err := r.db.QueryRow(ctx, query,
+		playlist.Owner,
+		playlist.Title,
+		playlist.Description,
+		playlist.Visibility,
+	).Scan(&playlist.ID, &playlist.CreatedAt, &playlist.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("playlist created with ID: ", playlist.ID)
+	return nil
+}
+
+func (r *PlaylistRepository) GetPlaylist(ctx context.Context, playlistID int) (*PlaylistModel, error) {
+	query := fmt.Sprintf(`
+		SELECT id, owner, title, description, visibility, created_at, updated_at
+		FROM %s
+		WHERE id = $1
+	`, playlistsTable)
+
+	var playlist PlaylistModel
+	err := r.db.QueryRow(ctx, query, playlistID).Scan(
+		&playlist.ID,
+		&playlist.Owner,
+		&playlist.Title,
+		&playlist.Description,
+		&playlist.Visibility,
+		&playlist.CreatedAt,
+		&playlist.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPlaylistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &playlist, nil
+}
+
+// GetPlaylists returns public playlists together with the ones owned by the user.
+// Unlisted playlists of other users are reachable by ID only.
+func (r *PlaylistRepository) GetPlaylists(ctx context.Context, filter PlaylistFilter, userID string, page, limit int) ([]*PlaylistModel, *common.PaginationMetadata, error) {
+	page = max(1, page)
+	limit = min(50, max(1, limit))
+
+	totalQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s
+		WHERE
+			($1::text IS NULL OR owner = $1) AND
+			(visibility = 'public' OR owner = $2)
+	`, playlistsTable)
+
+	query := fmt.Sprintf(`
+		SELECT id, owner, title, description, visibility, created_at, updated_at
+		FROM %s
+		WHERE
+			($1::text IS NULL OR owner = $1) AND
+			(visibility = 'public' OR owner = $2)
+		ORDER BY id
+		LIMIT $3 OFFSET $4
+	`, playlistsTable)
+
+	var totalCount int
+	if err := r.db.QueryRow(ctx, totalQuery, filter.Owner, userID).Scan(&totalCount); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := common.CalculateMetadata(totalCount, page, limit)
+
+	rows, err := r.db.Query(ctx, query, filter.Owner, userID, limit, max(0, page-1)*limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var playlists []*PlaylistModel
+	for rows.Next() {
+		var playlist PlaylistModel
+		err = rows.Scan(
+			&playlist.ID,
+			&playlist.Owner,
+			&playlist.Title,
+			&playlist.Description,
+			&playlist.Visibility,
+			&playlist.CreatedAt,
+			&playlist.UpdatedAt,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		playlists = append(playlists, &playlist)
+	}
+
+	return playlists, &metadata, rows.Err()
+}
+
+func (r *PlaylistRepository) UpdatePlaylist(ctx context.Context, dto UpdatePlaylistDTO) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET
+			title = COALESCE($1, title),
+			description = COALESCE($2, description),
+			visibility = COALESCE($3, visibility),
+			updated_at = NOW()
+		WHERE id = $4
+	`, playlistsTable)
+
+	tag, err := r.db.Exec(ctx, query, dto.Title, dto.Description, dto.Visibility, dto.PlaylistID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrPlaylistNotFound
+	}
+
+	logging.FromContext(ctx).Debug("playlist updated with ID: ", dto.PlaylistID)
+	return nil
+}
+
+func (r *PlaylistRepository) DeletePlaylist(ctx context.Context, playlistID int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, playlistsTable)
+	if _, err := r.db.Exec(ctx, query, playlistID); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("playlist deleted with ID: ", playlistID)
+	return nil
+}
+
+func (r *PlaylistRepository) GetPlaylistSongs(ctx context.Context, playlistID int, page, limit int) ([]*PlaylistItemModel, *common.PaginationMetadata, error) {
+	page = max(1, page)
+	limit = min(50, max(1, limit))
+
+	totalQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE playlist_id = $1`, playlistItemsTable)
+
+	query := fmt.Sprintf(`
+		SELECT
+			pi.id,
+			pi.playlist_id,
+			pi.song_id,
+			pi.position,
+			pi.added_at,
+			s.song,
+			s."group",
+			s.release_date,
+			s."text",
+			s.link
+		FROM %s pi
+		JOIN %s s ON s.id = pi.song_id
+		WHERE pi.playlist_id = $1
+		ORDER BY pi.position, pi.id
+		LIMIT $2 OFFSET $3
+	`, playlistItemsTable, songsTable)
+
+	var totalCount int
+	if err := r.db.QueryRow(ctx, totalQuery, playlistID).Scan(&totalCount); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := common.CalculateMetadata(totalCount, page, limit)
+
+	offset := max(0, page-1) * limit
+	rows, err := r.db.Query(ctx, query, playlistID, limit, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var items []*PlaylistItemModel
+	for rows.Next() {
+		item := PlaylistItemModel{Song: new(song.SongModel)}
+		err = rows.Scan(
+			&item.ID,
+			&item.PlaylistID,
+			&item.SongID,
+			&item.Position,
+			&item.AddedAt,
+			&item.Song.Song,
+			&item.Song.Group,
+			&item.Song.ReleaseDate,
+			&item.Song.Text,
+			&item.Song.Link,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Positions may have gaps after songs were deleted from the library,
+		// so report the ordinal position within the playlist instead.
+		item.Song.ID = item.SongID
+		item.Position = offset + len(items)
+		items = append(items, &item)
+	}
+
+	return items, &metadata, rows.Err()
+}
+
+// AddItem inserts the song at the given position, shifting the following items down.
+// A nil or out of range position appends the song to the end of the playlist.
+func (r *PlaylistRepository) AddItem(ctx context.Context, playlistID, songID int, position *int) (*PlaylistItemModel, error) {
+	item := &PlaylistItemModel{
+		PlaylistID: playlistID,
+		SongID:     songID,
+	}
+
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		count, err := r.lockItems(ctx, tx, playlistID)
+		if err != nil {
+			return err
+		}
+
+		var exists bool
+		query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, songsTable)
+		if err := tx.QueryRow(ctx, query, songID).Scan(&exists); err != nil {
+			return err
+		}
+
+		if !exists {
+			return ErrSongNotFound
+		}
+
+		item.Position = count
+		if position != nil {
+			item.Position = min(count, max(0, *position))
+		}
+
+		query = fmt.Sprintf(`
+			UPDATE %s SET position = position + 1
+			WHERE playlist_id = $1 AND position >= $2
+		`, playlistItemsTable)
+		if _, err := tx.Exec(ctx, query, playlistID, item.Position); err != nil {
+			return err
+		}
+
+		query = fmt.Sprintf(`
+			INSERT INTO %s (playlist_id, song_id, position)
+			VALUES ($1, $2, $3)
+			RETURNING id, added_at
+		`, playlistItemsTable)
+
+		return tx.QueryRow(ctx, query, playlistID, songID, item.Position).Scan(&item.ID, &item.AddedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("song ", songID, " added to playlist ", playlistID, " at position ", item.Position)
+	return item, nil
+}
+
+func (r *PlaylistRepository) RemoveItem(ctx context.Context, playlistID, itemID int) error {
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := r.lockItems(ctx, tx, playlistID); err != nil {
+			return err
+		}
+
+		var position int
+		query := fmt.Sprintf(`
+			DELETE FROM %s
+			WHERE playlist_id = $1 AND id = $2
+			RETURNING position
+		`, playlistItemsTable)
+		err := tx.QueryRow(ctx, query, playlistID, itemID).Scan(&position)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		query = fmt.Sprintf(`
+			UPDATE %s SET position = position - 1
+			WHERE playlist_id = $1 AND position > $2
+		`, playlistItemsTable)
+		_, err = tx.Exec(ctx, query, playlistID, position)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("item ", itemID, " removed from playlist ", playlistID)
+	return nil
+}
+
+// MoveItem moves the item to the given position, shifting the items in between.
+// Positions past the end of the playlist move the item to the last place.
+func (r *PlaylistRepository) MoveItem(ctx context.Context, playlistID, itemID, position int) (*PlaylistItemModel, error) {
+	item := &PlaylistItemModel{
+		ID:         itemID,
+		PlaylistID: playlistID,
+	}
+
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		count, err := r.lockItems(ctx, tx, playlistID)
+		if err != nil {
+			return err
+		}
+
+		var from int
+		query := fmt.Sprintf(`
+			SELECT position, song_id, added_at
+			FROM %s
+			WHERE playlist_id = $1 AND id = $2
+		`, playlistItemsTable)
+		err = tx.QueryRow(ctx, query, playlistID, itemID).Scan(&from, &item.SongID, &item.AddedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		to := min(count-1, max(0, position))
+		item.Position = to
+		if from == to {
+			return nil
+		}
+
+		if from < to {
+			query = fmt.Sprintf(`
+				UPDATE %s SET position = CASE WHEN id = $2 THEN $4 ELSE position - 1 END
+				WHERE playlist_id = $1 AND position BETWEEN $3 AND $4
+			`, playlistItemsTable)
+		} else {
+			query = fmt.Sprintf(`
+				UPDATE %s SET position = CASE WHEN id = $2 THEN $4 ELSE position + 1 END
+				WHERE playlist_id = $1 AND position BETWEEN $4 AND $3
+			`, playlistItemsTable)
+		}
+
+		_, err = tx.Exec(ctx, query, playlistID, itemID, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("item ", itemID, " of playlist ", playlistID, " moved to position ", item.Position)
+	return item, nil
+}
+
+// lockItems serializes concurrent edits of the playlist by locking its row, marks
+// it as updated and compacts item positions to 0..n-1, so that shifts made by the
+// caller always work on a dense range. It returns the number of items in the playlist.
+func (r *PlaylistRepository) lockItems(ctx context.Context, tx pgx.Tx, playlistID int) (int, error) {
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE id = $1 FOR UPDATE`, playlistsTable)
+	if err := tx.QueryRow(ctx, query, playlistID).Scan(new(int)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrPlaylistNotFound
+		}
+
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`
+		UPDATE %[1]s pi SET position = ordered.rn - 1
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) AS rn
+			FROM %[1]s
+			WHERE playlist_id = $1
+		) ordered
+		WHERE pi.id = ordered.id AND pi.position <> ordered.rn - 1
+	`, playlistItemsTable)
+	if _, err := tx.Exec(ctx, query, playlistID); err != nil {
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`
+		UPDATE %s SET updated_at = NOW() WHERE id = $1
+	`, playlistsTable)
+	if _, err := tx.Exec(ctx, query, playlistID); err != nil {
+		return 0, err
+	}
+
+	var count int
+	query = fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE playlist_id = $1`, playlistItemsTable)
+	if err := tx.QueryRow(ctx, query, playlistID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
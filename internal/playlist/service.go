@@ -0,0 +1,114 @@
+package playlist
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+)
+
+type PlaylistService struct {
+	config *config.Config
+	repo   *PlaylistRepository
+}
+
+func NewPlaylistService(cfg *config.Config, repo *PlaylistRepository) *PlaylistService {
+	return &PlaylistService{
+		config: cfg,
+		repo:   repo,
+	}
+}
+
+func (s *PlaylistService) CreatePlaylist(ctx context.Context, playlist *PlaylistModel) error {
+	if playlist.Owner == "" {
+		return ErrUnauthorized
+	}
+
+	if playlist.Visibility == "" {
+		playlist.Visibility = VisibilityPrivate
+	}
+
+	return s.repo.CreatePlaylist(ctx, playlist)
+}
+
+func (s *PlaylistService) GetPlaylist(ctx context.Context, playlistID int, userID string) (*PlaylistModel, error) {
+	playlist, err := s.repo.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Private playlists are reported as missing to avoid leaking their existence.
+	if !playlist.CanView(userID) {
+		return nil, ErrPlaylistNotFound
+	}
+
+	return playlist, nil
+}
+
+func (s *PlaylistService) GetPlaylists(ctx context.Context, filter PlaylistFilter, userID string, page, limit int) ([]*PlaylistModel, *common.PaginationMetadata, error) {
+	return s.repo.GetPlaylists(ctx, filter, userID, page, limit)
+}
+
+func (s *PlaylistService) UpdatePlaylist(ctx context.Context, dto UpdatePlaylistDTO, userID string) error {
+	if _, err := s.getEditable(ctx, dto.PlaylistID, userID); err != nil {
+		return err
+	}
+
+	return s.repo.UpdatePlaylist(ctx, dto)
+}
+
+func (s *PlaylistService) DeletePlaylist(ctx context.Context, playlistID int, userID string) error {
+	if _, err := s.getEditable(ctx, playlistID, userID); err != nil {
+		return err
+	}
+
+	return s.repo.DeletePlaylist(ctx, playlistID)
+}
+
+func (s *PlaylistService) GetPlaylistSongs(ctx context.Context, playlistID int, userID string, page, limit int) ([]*PlaylistItemModel, *common.PaginationMetadata, error) {
+	if _, err := s.GetPlaylist(ctx, playlistID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	return s.repo.GetPlaylistSongs(ctx, playlistID, page, limit)
+}
+
+func (s *PlaylistService) AddItem(ctx context.Context, playlistID, songID int, position *int, userID string) (*PlaylistItemModel, error) {
+	if _, err := s.getEditable(ctx, playlistID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.AddItem(ctx, playlistID, songID, position)
+}
+
+func (s *PlaylistService) RemoveItem(ctx context.Context, playlistID, itemID int, userID string) error {
+	if _, err := s.getEditable(ctx, playlistID, userID); err != nil {
+		return err
+	}
+
+	return s.repo.RemoveItem(ctx, playlistID, itemID)
+}
+
+func (s *PlaylistService) MoveItem(ctx context.Context, playlistID, itemID, position int, userID string) (*PlaylistItemModel, error) {
+	if _, err := s.getEditable(ctx, playlistID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.MoveItem(ctx, playlistID, itemID, position)
+}
+
+func (s *PlaylistService) getEditable(ctx context.Context, playlistID int, userID string) (*PlaylistModel, error) {
+	if userID == "" {
+		return nil, ErrUnauthorized
+	}
+
+	playlist, err := s.GetPlaylist(ctx, playlistID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !playlist.CanEdit(userID) {
+		return nil, ErrForbidden
+	}
+
+	return playlist, nil
+}
@@ -0,0 +1,98 @@
+package song
+
+import (
+	"sync"
+	"time"
+)
+
+// States of a circuit breaker.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// breaker stops calls to a failing dependency. It opens after threshold consecutive failures
+// and rejects calls for the cooldown, after which a single trial call is let through:
+// its success closes the breaker, its failure opens it again.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: max(1, threshold),
+		cooldown:  cooldown,
+	}
+}
+
+// allow tells whether a call may be made. A call that is allowed must be followed by record or release.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state() {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+
+		b.trial = true
+	}
+
+	return true
+}
+
+// record records the outcome of an allowed call.
+func (b *breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if ok {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// release ends an allowed call whose outcome tells nothing about the dependency,
+// such as one given up by the caller, leaving the failures as they are.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// status returns the state of the breaker along with the number of consecutive failures.
+func (b *breaker) status() (string, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state(), b.failures
+}
+
+// state returns the state of the breaker, the lock must be held.
+func (b *breaker) state() string {
+	switch {
+	case b.failures < b.threshold:
+		return BreakerClosed
+	case time.Since(b.openedAt) < b.cooldown:
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
@@ -0,0 +1,155 @@
+package song
+
+import (
+	"testing"
+	"time"
+)
+
+func assertBreaker(t *testing.T, b *breaker, wantState string, wantFailures int) {
+	t.Helper()
+
+	state, failures := b.status()
+	if state != wantState || failures != wantFailures {
+		t.Fatalf("status() = %s, %d, want %s, %d", state, failures, wantState, wantFailures)
+	}
+}
+
+// expire makes the cooldown of an open breaker run out.
+func expire(b *breaker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.openedAt = time.Now().Add(-b.cooldown)
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b := newBreaker(3, time.Hour)
+
+	for i := 1; i < 3; i++ {
+		if !b.allow() {
+			t.Fatalf("call %d rejected while closed", i)
+		}
+
+		b.record(false)
+		assertBreaker(t, b, BreakerClosed, i)
+	}
+
+	if !b.allow() {
+		t.Fatal("call rejected while closed")
+	}
+
+	b.record(false)
+	assertBreaker(t, b, BreakerOpen, 3)
+
+	if b.allow() {
+		t.Fatal("call allowed while open")
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := newBreaker(2, time.Hour)
+
+	b.allow()
+	b.record(false)
+	b.allow()
+	b.record(true)
+	assertBreaker(t, b, BreakerClosed, 0)
+
+	b.allow()
+	b.record(false)
+	assertBreaker(t, b, BreakerClosed, 1)
+}
+
+func TestBreakerHalfOpenLetsOneTrialThrough(t *testing.T) {
+	b := newBreaker(1, time.Hour)
+
+	b.allow()
+	b.record(false)
+	expire(b)
+	assertBreaker(t, b, BreakerHalfOpen, 1)
+
+	if !b.allow() {
+		t.Fatal("trial call rejected while half open")
+	}
+
+	if b.allow() {
+		t.Fatal("second call allowed during the trial")
+	}
+}
+
+func TestBreakerTrialOutcome(t *testing.T) {
+	t.Run("success closes", func(t *testing.T) {
+		b := newBreaker(2, time.Hour)
+		b.allow()
+		b.record(false)
+		b.allow()
+		b.record(false)
+		expire(b)
+
+		b.allow()
+		b.record(true)
+		assertBreaker(t, b, BreakerClosed, 0)
+
+		if !b.allow() {
+			t.Fatal("call rejected after the breaker closed")
+		}
+	})
+
+	t.Run("failure opens again", func(t *testing.T) {
+		b := newBreaker(2, time.Hour)
+		b.allow()
+		b.record(false)
+		b.allow()
+		b.record(false)
+		expire(b)
+
+		b.allow()
+		b.record(false)
+		assertBreaker(t, b, BreakerOpen, 3)
+
+		if b.allow() {
+			t.Fatal("call allowed after the trial failed")
+		}
+	})
+}
+
+func TestBreakerRelease(t *testing.T) {
+	t.Run("closed", func(t *testing.T) {
+		b := newBreaker(2, time.Hour)
+		b.allow()
+		b.record(false)
+
+		b.allow()
+		b.release()
+		assertBreaker(t, b, BreakerClosed, 1)
+	})
+
+	t.Run("half open", func(t *testing.T) {
+		b := newBreaker(1, time.Hour)
+		b.allow()
+		b.record(false)
+		expire(b)
+
+		// A released trial neither closes nor reopens the breaker,
+		// and lets the next call be the trial.
+		if !b.allow() {
+			t.Fatal("trial call rejected while half open")
+		}
+
+		b.release()
+		assertBreaker(t, b, BreakerHalfOpen, 1)
+
+		if !b.allow() {
+			t.Fatal("call rejected after the trial was released")
+		}
+	})
+}
+
+func TestBreakerThresholdAtLeastOne(t *testing.T) {
+	b := newBreaker(0, time.Hour)
+	assertBreaker(t, b, BreakerClosed, 0)
+
+	b.allow()
+	b.record(false)
+	assertBreaker(t, b, BreakerOpen, 1)
+}
@@ -0,0 +1,126 @@
+package song
+
+import (
+	"context"
+	"effective-mobile/go/internal/tracing"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const detailRequestTimeout = 10 * time.Second
+
+type songDetails struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// fetchDetails requests release date, lyrics and link of the song from the detail API.
+func (s *SongService) fetchDetails(ctx context.Context, group, song string) (*songDetails, error) {
+	outcome := detailOutcomeError
+	defer func(start time.Time) {
+		detailRequestDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+	}(time.Now())
+
+	if !s.detailBreaker.allow() {
+		outcome = detailOutcomeRejected
+		return nil, ErrDetailAPIUnavailable
+	}
+
+	defer func() {
+		switch {
+		case outcome == detailOutcomeOK:
+			s.detailBreaker.record(true)
+		case errors.Is(ctx.Err(), context.Canceled):
+			// Requests given up by the caller tell nothing about the API.
+			s.detailBreaker.release()
+		default:
+			// Songs unknown to the API do not count as failures.
+			s.detailBreaker.record(strings.HasPrefix(outcome, "http_4"))
+		}
+	}()
+
+	q := url.Values{}
+	q.Set("group", group)
+	q.Set("song", song)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.SongDetailAPI+"/info?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+			outcome = detailOutcomeTimeout
+		}
+
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		outcome = fmt.Sprintf("http_%dxx", resp.StatusCode/100)
+		return nil, fmt.Errorf("detail api responded with status %d", resp.StatusCode)
+	}
+
+	var details songDetails
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		outcome = detailOutcomeInvalidResponse
+		return nil, err
+	}
+
+	outcome = detailOutcomeOK
+	return &details, nil
+}
+
+// CheckDetailAPI reports the detail API as unavailable while its circuit breaker is open.
+func (s *SongService) CheckDetailAPI(ctx context.Context) error {
+	state, failures := s.detailBreaker.status()
+	if state == BreakerOpen {
+		return fmt.Errorf("%w: circuit breaker is open after %d consecutive failures", ErrDetailAPIUnavailable, failures)
+	}
+
+	return nil
+}
+
+// enrich fills release date, lyrics and link of the song that are not set yet
+// with the data from the detail API.
+func (s *SongService) enrich(ctx context.Context, song *SongModel) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.enrich")
+	defer span.End()
+
+	enrichmentQueueDepth.Inc()
+	defer enrichmentQueueDepth.Dec()
+
+	details, err := s.fetchDetails(ctx, song.Group, song.Song)
+	if err != nil {
+		return err
+	}
+
+	if song.ReleaseDate.IsZero() && details.ReleaseDate != "" {
+		releaseDate, err := time.Parse(time.DateOnly, details.ReleaseDate)
+		if err != nil {
+			return err
+		}
+
+		song.ReleaseDate = releaseDate
+	}
+
+	if len(song.Text) == 0 && details.Text != "" {
+		song.Text = strings.Split(details.Text, "\n\n")
+	}
+
+	if song.Link == "" {
+		song.Link = details.Link
+	}
+
+	return nil
+}
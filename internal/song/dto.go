@@ -2,6 +2,8 @@ package song
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -23,6 +25,153 @@ type SongDTO struct {
 	ReleaseDate DateOnly `json:"release_date" time_format:"2006-01-02"`
 	Text        []string `json:"text"`
 	Link        string   `json:"link"`
+	// Whether the lyrics are explicit
+	Explicit bool `json:"explicit"`
+}
+
+// swagger:model CoupletDTO
+type CoupletDTO struct {
+	// Stable ID of the couplet
+	ID int64 `json:"id"`
+	// Zero-based position of the couplet in the lyrics
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+	// Translation of the couplet, present when lyrics are interleaved with a translation
+	Translation *string `json:"translation,omitempty"`
+}
+
+// swagger:model TranslationDTO
+type TranslationDTO struct {
+	// example: en
+	Language string `json:"language"`
+	// Number of translated couplets
+	Couplets  int       `json:"couplets"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// swagger:model SongDetailsDTO
+type SongDetailsDTO struct {
+	SongDTO
+	// Explicit flag set by hand, null while it is detected from the lyrics
+	ExplicitOverride *bool `json:"explicit_override"`
+	// Present when lyrics are included
+	Lyrics *LyricsStructureDTO `json:"lyrics,omitempty"`
+	// Present when links are included
+	Links *SongLinksDTO `json:"links,omitempty"`
+	// Present when activity is included
+	Activity *SongActivityDTO `json:"activity,omitempty"`
+	// Present when languages are included
+	Languages []LanguageDTO `json:"languages,omitempty"`
+	// Present when tags are included
+	Tags *[]string `json:"tags,omitempty"`
+	// Number of versions of the song, present when revisions are included
+	Revisions *int `json:"revisions,omitempty"`
+}
+
+type LanguageDTO struct {
+	// ISO 639-1 code of the language, "und" when it could not be determined
+	// example: en
+	Language string `json:"language"`
+	// Share of the lyrics written in the language
+	// example: 0.85
+	Confidence float64 `json:"confidence"`
+}
+
+type LyricsStructureDTO struct {
+	Couplets int `json:"couplets"`
+	// Number of lines of every couplet
+	Lines []int `json:"lines"`
+	Words int   `json:"words"`
+}
+
+type SongLinksDTO struct {
+	// example: /songs/1
+	Self string `json:"self"`
+	// example: /songs/1/lyrics
+	Lyrics string `json:"lyrics"`
+	// Link to the song on the web
+	// example: https://example.com
+	Source string `json:"source,omitempty"`
+}
+
+type SongActivityDTO struct {
+	Plays int64 `json:"plays"`
+	Likes int64 `json:"likes"`
+}
+
+// swagger:model LyricsStatsDTO
+type LyricsStatsDTO struct {
+	// Number of songs the statistics cover
+	Songs       int `json:"songs"`
+	Couplets    int `json:"couplets"`
+	Lines       int `json:"lines"`
+	Words       int `json:"words"`
+	UniqueWords int `json:"unique_words"`
+	// Share of distinct words among all words
+	// example: 0.42
+	UniqueWordRatio float64 `json:"unique_word_ratio"`
+	// Most frequent words other than stop words
+	TopWords []WordCountDTO `json:"top_words"`
+	// Estimated reading time in seconds
+	ReadingTime int `json:"reading_time"`
+	// Share of lines repeating an earlier line of the same song
+	// example: 0.25
+	RepetitionScore float64 `json:"repetition_score"`
+	// Languages whose stop words are left out of the top words
+	StopWords  []string  `json:"stop_words"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+type WordCountDTO struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// swagger:model LibraryStatsDTO
+type LibraryStatsDTO struct {
+	Songs  int64 `json:"songs"`
+	Groups int64 `json:"groups"`
+	// Songs without lyrics
+	MissingLyrics ShareDTO `json:"missing_lyrics"`
+	// Songs without a link
+	MissingLinks ShareDTO `json:"missing_links"`
+	// Groups with the most songs
+	SongsPerGroup     []GroupCountDTO  `json:"songs_per_group"`
+	ReleasesPerYear   []YearCountDTO   `json:"releases_per_year"`
+	ReleasesPerDecade []DecadeCountDTO `json:"releases_per_decade"`
+	// Songs added to the library per period along with the running total
+	Growth []GrowthDTO `json:"growth"`
+}
+
+type ShareDTO struct {
+	Songs int64 `json:"songs"`
+	// example: 0.12
+	Share float64 `json:"share"`
+}
+
+type GroupCountDTO struct {
+	Group string `json:"group"`
+	Songs int64  `json:"songs"`
+}
+
+type YearCountDTO struct {
+	Year  int   `json:"year"`
+	Songs int64 `json:"songs"`
+}
+
+type DecadeCountDTO struct {
+	// First year of the decade
+	// example: 1990
+	Decade int   `json:"decade"`
+	Songs  int64 `json:"songs"`
+}
+
+type GrowthDTO struct {
+	// Start of the period
+	// example: 2024-01-01
+	Period DateOnly `json:"period"`
+	Added  int64    `json:"added"`
+	Total  int64    `json:"total"`
 }
 
 // swagger:type DateOnly
@@ -48,3 +197,138 @@ func (d *DateOnly) UnmarshalJSON(data []byte) error {
 	*d = DateOnly(t)
 	return nil
 }
+
+func (s *SongModel) ToDTO() SongDTO {
+	return SongDTO{
+		ID:          s.ID,
+		Song:        s.Song,
+		Group:       s.Group,
+		ReleaseDate: DateOnly(s.ReleaseDate),
+		Text:        s.Text,
+		Link:        s.Link,
+		Explicit:    s.Explicit,
+	}
+}
+
+func (d *SongDetails) ToDTO() SongDetailsDTO {
+	dto := SongDetailsDTO{SongDTO: d.Song.ToDTO(), ExplicitOverride: d.Song.ExplicitOverride}
+
+	for _, include := range d.Includes {
+		switch include {
+		case IncludeLyrics:
+			lyrics := LyricsStructureDTO{Couplets: len(d.Song.Text), Lines: make([]int, 0, len(d.Song.Text))}
+			for _, couplet := range d.Song.Text {
+				lyrics.Lines = append(lyrics.Lines, len(strings.Split(strings.TrimSpace(couplet), "\n")))
+				lyrics.Words += len(strings.Fields(couplet))
+			}
+
+			dto.Lyrics = &lyrics
+		case IncludeLinks:
+			dto.Links = &SongLinksDTO{
+				Self:   fmt.Sprintf("/songs/%d", d.Song.ID),
+				Lyrics: fmt.Sprintf("/songs/%d/lyrics", d.Song.ID),
+				Source: d.Song.Link,
+			}
+		case IncludeActivity:
+			if d.Activity != nil {
+				dto.Activity = &SongActivityDTO{Plays: d.Activity.Plays, Likes: d.Activity.Likes}
+			}
+		case IncludeLanguages:
+			dto.Languages = make([]LanguageDTO, 0, len(d.Languages))
+			for _, language := range d.Languages {
+				dto.Languages = append(dto.Languages, LanguageDTO{Language: language.Language, Confidence: language.Confidence})
+			}
+		case IncludeTags:
+			tags := append(make([]string, 0, len(d.Tags)), d.Tags...)
+			dto.Tags = &tags
+		case IncludeRevisions:
+			revisions := d.Revisions
+			dto.Revisions = &revisions
+		}
+	}
+
+	return dto
+}
+
+func (c *Couplet) ToDTO() CoupletDTO {
+	return CoupletDTO{
+		ID:          c.ID,
+		Index:       c.Index,
+		Text:        c.Text,
+		Translation: c.Translation,
+	}
+}
+
+func (t *TranslationSummary) ToDTO() TranslationDTO {
+	return TranslationDTO{
+		Language:  t.Language,
+		Couplets:  t.Couplets,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func (s *LyricsStats) ToDTO() LyricsStatsDTO {
+	topWords := make([]WordCountDTO, 0, len(s.TopWords))
+	for _, word := range s.TopWords {
+		topWords = append(topWords, WordCountDTO{Word: word.Word, Count: word.Count})
+	}
+
+	return LyricsStatsDTO{
+		Songs:           s.Songs,
+		Couplets:        s.Couplets,
+		Lines:           s.Lines,
+		Words:           s.Words,
+		UniqueWords:     s.UniqueWords,
+		UniqueWordRatio: s.UniqueWordRatio,
+		TopWords:        topWords,
+		ReadingTime:     int(s.ReadingTime.Round(time.Second).Seconds()),
+		RepetitionScore: s.RepetitionScore,
+		StopWords:       s.StopWords,
+		ComputedAt:      s.ComputedAt,
+	}
+}
+
+func (s *LibraryStats) ToDTO() LibraryStatsDTO {
+	share := func(songs int64) ShareDTO {
+		if s.Songs == 0 {
+			return ShareDTO{Songs: songs}
+		}
+
+		return ShareDTO{Songs: songs, Share: float64(songs) / float64(s.Songs)}
+	}
+
+	dto := LibraryStatsDTO{
+		Songs:             s.Songs,
+		Groups:            s.Groups,
+		MissingLyrics:     share(s.MissingLyrics),
+		MissingLinks:      share(s.MissingLinks),
+		SongsPerGroup:     make([]GroupCountDTO, 0, len(s.TopGroups)),
+		ReleasesPerYear:   make([]YearCountDTO, 0, len(s.Years)),
+		ReleasesPerDecade: make([]DecadeCountDTO, 0),
+		Growth:            make([]GrowthDTO, 0, len(s.Growth)),
+	}
+
+	for _, group := range s.TopGroups {
+		dto.SongsPerGroup = append(dto.SongsPerGroup, GroupCountDTO{Group: group.Group, Songs: group.Songs})
+	}
+
+	// Years are in chronological order, so decades are too.
+	for _, year := range s.Years {
+		dto.ReleasesPerYear = append(dto.ReleasesPerYear, YearCountDTO{Year: year.Year, Songs: year.Songs})
+
+		decade := year.Year - year.Year%10
+		if n := len(dto.ReleasesPerDecade); n > 0 && dto.ReleasesPerDecade[n-1].Decade == decade {
+			dto.ReleasesPerDecade[n-1].Songs += year.Songs
+		} else {
+			dto.ReleasesPerDecade = append(dto.ReleasesPerDecade, DecadeCountDTO{Decade: decade, Songs: year.Songs})
+		}
+	}
+
+	var total int64
+	for _, period := range s.Growth {
+		total += period.Songs
+		dto.Growth = append(dto.Growth, GrowthDTO{Period: DateOnly(period.Period), Added: period.Songs, Total: total})
+	}
+
+	return dto
+}
@@ -3,5 +3,16 @@ package song
 import "errors"
 
 var (
-	ErrServiceUnavailable = errors.New("service is unavailable")
+	ErrServiceUnavailable   = errors.New("service is unavailable")
+	ErrDetailAPIUnavailable = errors.New("detail api is unavailable")
+	ErrSongNotFound         = errors.New("song not found")
+	ErrCoupletNotFound      = errors.New("couplet not found")
+	ErrTranslationNotFound  = errors.New("translation not found")
+	ErrInvalidLanguage      = errors.New("invalid language code")
+	ErrMisalignedLyrics     = errors.New("translation must have as many couplets as the lyrics")
+	ErrGroupNotFound        = errors.New("group not found")
+	ErrInvalidStopWords     = errors.New("invalid stop words")
+	ErrInvalidSort          = errors.New("invalid sort")
+	ErrInvalidTags          = errors.New("invalid tags")
+	ErrUnknownImportFormat  = errors.New("unknown import format, pass the format parameter or a matching Content-Type")
 )
@@ -0,0 +1,73 @@
+package song
+
+import (
+	"context"
+	"effective-mobile/go/internal/outbox"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	EventSongCreated  = "song.created"
+	EventSongUpdated  = "song.updated"
+	EventSongDeleted  = "song.deleted"
+	EventSongEnriched = "song.enriched"
+)
+
+// SongEventTypes lists every type of song events.
+var SongEventTypes = []string{EventSongCreated, EventSongUpdated, EventSongDeleted, EventSongEnriched}
+
+// SongEventPayload is the payload of song events.
+type SongEventPayload struct {
+	SongID int `json:"song_id"`
+	// Song after the change, absent for song.deleted
+	Song *SongDTO `json:"song,omitempty"`
+}
+
+// newSongEvent returns the outbox event of a change to the song, keyed by the song ID.
+func newSongEvent(eventType string, songID int, song *SongModel) (*outbox.Event, error) {
+	payload := SongEventPayload{SongID: songID}
+	if song != nil {
+		dto := song.ToDTO()
+		payload.Song = &dto
+	}
+
+	return outbox.NewEvent(eventType, strconv.Itoa(songID), payload)
+}
+
+// writeSongEvents records the events of a change to the songs within its transaction.
+func (r *SongRepository) writeSongEvents(ctx context.Context, tx pgx.Tx, eventType string, songs []*SongModel) error {
+	events := make([]*outbox.Event, 0, len(songs))
+	for _, song := range songs {
+		event, err := newSongEvent(eventType, song.ID, song)
+		if err != nil {
+			return fmt.Errorf("failed to create %s event: %w", eventType, err)
+		}
+
+		events = append(events, event)
+	}
+
+	return outbox.Write(ctx, tx, events...)
+}
+
+// writeSongEvent records the event of a change to the song within its transaction,
+// along with the song as the transaction sees it unless the song has been deleted.
+func (r *SongRepository) writeSongEvent(ctx context.Context, tx pgx.Tx, eventType string, songID int) error {
+	var song *SongModel
+	if eventType != EventSongDeleted {
+		var err error
+		song, err = r.getSong(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+	}
+
+	event, err := newSongEvent(eventType, songID, song)
+	if err != nil {
+		return fmt.Errorf("failed to create %s event: %w", eventType, err)
+	}
+
+	return outbox.Write(ctx, tx, event)
+}
@@ -0,0 +1,177 @@
+package song
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExportFormatCSV   = "csv"
+	ExportFormatJSON  = "json"
+	ExportFormatJSONL = "jsonl"
+)
+
+const (
+	// LyricsArray exports lyrics as a list of couplets. CSV falls back to LyricsJoined.
+	LyricsArray = "array"
+	// LyricsJoined exports lyrics as a single string with couplets separated by an empty line.
+	LyricsJoined = "joined"
+	// LyricsNone leaves lyrics out of the export.
+	LyricsNone = "none"
+)
+
+const coupletSeparator = "\n\n"
+
+type ExportOptions struct {
+	Format  string
+	Columns []string
+	Lyrics  string
+}
+
+// WithText reports whether the export needs lyrics to be loaded at all.
+func (o ExportOptions) WithText() bool {
+	if o.Lyrics == LyricsNone {
+		return false
+	}
+
+	for _, column := range o.Columns {
+		if column == "text" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExportWriter encodes songs one at a time, so the export never holds more than a single row.
+type ExportWriter interface {
+	Begin() error
+	Write(song *SongModel) error
+	End() error
+}
+
+func ExportContentType(format string) string {
+	switch format {
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case ExportFormatJSONL:
+		return "application/x-ndjson"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+func NewExportWriter(w io.Writer, opts ExportOptions) (ExportWriter, error) {
+	columns := opts.Columns
+	if opts.Lyrics == LyricsNone {
+		columns = WithoutLyrics(columns)
+	}
+
+	switch opts.Format {
+	case ExportFormatCSV:
+		return &csvExportWriter{w: csv.NewWriter(w), columns: columns}, nil
+	case ExportFormatJSONL:
+		return &jsonExportWriter{w: w, columns: columns, lyrics: opts.Lyrics}, nil
+	case ExportFormatJSON:
+		return &jsonExportWriter{w: w, columns: columns, lyrics: opts.Lyrics, array: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %q", opts.Format)
+	}
+}
+
+type csvExportWriter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (e *csvExportWriter) Begin() error {
+	return e.w.Write(e.columns)
+}
+
+func (e *csvExportWriter) Write(song *SongModel) error {
+	record := make([]string, 0, len(e.columns))
+	for _, column := range e.columns {
+		switch column {
+		case "id":
+			record = append(record, strconv.Itoa(song.ID))
+		case "song":
+			record = append(record, song.Song)
+		case "group":
+			record = append(record, song.Group)
+		case "release_date":
+			record = append(record, song.ReleaseDate.Format(time.DateOnly))
+		case "text":
+			record = append(record, strings.Join(song.Text, coupletSeparator))
+		case "link":
+			record = append(record, song.Link)
+		case "explicit":
+			record = append(record, strconv.FormatBool(song.Explicit))
+		}
+	}
+
+	if err := e.w.Write(record); err != nil {
+		return err
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExportWriter writes an object per song, either one per line or as a single array.
+type jsonExportWriter struct {
+	w       io.Writer
+	columns []string
+	lyrics  string
+	array   bool
+	written bool
+}
+
+func (e *jsonExportWriter) Begin() error {
+	if e.array {
+		_, err := io.WriteString(e.w, "[")
+		return err
+	}
+
+	return nil
+}
+
+func (e *jsonExportWriter) Write(song *SongModel) error {
+	var b strings.Builder
+
+	if e.array && e.written {
+		b.WriteByte(',')
+	}
+
+	encoded, err := SparseSongDTO{song: song, fields: e.columns, lyrics: e.lyrics}.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	b.Write(encoded)
+
+	if !e.array {
+		b.WriteByte('\n')
+	}
+
+	e.written = true
+	_, err = io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *jsonExportWriter) End() error {
+	if e.array {
+		_, err := io.WriteString(e.w, "]\n")
+		return err
+	}
+
+	return nil
+}
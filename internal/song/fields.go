@@ -0,0 +1,159 @@
+package song
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SongFields lists the fields of a song that can be selected, in their default order.
+var SongFields = []string{"id", "song", "group", "release_date", "text", "link", "explicit"}
+
+// SongIncludes lists the related data that can be embedded into a single song.
+var SongIncludes = []string{IncludeLyrics, IncludeLinks, IncludeActivity, IncludeLanguages, IncludeTags, IncludeRevisions}
+
+const (
+	// IncludeLyrics embeds the structure of the lyrics.
+	IncludeLyrics = "lyrics"
+	// IncludeLinks embeds links to the song and its related resources.
+	IncludeLinks = "links"
+	// IncludeActivity embeds the play and like totals of the song.
+	IncludeActivity = "activity"
+	// IncludeLanguages embeds the languages detected in the lyrics.
+	IncludeLanguages = "languages"
+	// IncludeTags embeds the tags of the song.
+	IncludeTags = "tags"
+	// IncludeRevisions embeds the number of versions of the song.
+	IncludeRevisions = "revisions"
+)
+
+// ParseSongFields validates a comma-separated list of fields,
+// returning every field when the list is empty.
+func ParseSongFields(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return SongFields, nil
+	}
+
+	return parseList(list, SongFields, "field")
+}
+
+// ParseSongIncludes validates a comma-separated list of includes.
+func ParseSongIncludes(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	return parseList(list, SongIncludes, "include")
+}
+
+// parseList splits a comma-separated list, rejecting unknown items and dropping repeated ones.
+func parseList(list string, allowed []string, kind string) ([]string, error) {
+	items := make([]string, 0, len(allowed))
+	seen := make(map[string]bool, len(allowed))
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if !slices.Contains(allowed, item) {
+			return nil, fmt.Errorf("unknown %s: %q", kind, item)
+		}
+
+		if !seen[item] {
+			seen[item] = true
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// WithoutLyrics returns the fields without the lyrics.
+func WithoutLyrics(fields []string) []string {
+	result := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field != "text" {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
+
+// songColumn returns the column of the field and the destination it is scanned into.
+func songColumn(field string, song *SongModel) (string, interface{}) {
+	switch field {
+	case "id":
+		return "s.id", &song.ID
+	case "song":
+		return "s.song", &song.Song
+	case "group":
+		return `s."group"`, &song.Group
+	case "release_date":
+		return "s.release_date", &song.ReleaseDate
+	case "text":
+		return `s."text"`, &song.Text
+	case "explicit":
+		return "s.explicit", &song.Explicit
+	default:
+		return "s.link", &song.Link
+	}
+}
+
+// songFieldValue returns the value of the field as it is encoded into JSON.
+func songFieldValue(song *SongModel, field string, lyrics string) interface{} {
+	switch field {
+	case "id":
+		return song.ID
+	case "song":
+		return song.Song
+	case "group":
+		return song.Group
+	case "release_date":
+		return DateOnly(song.ReleaseDate)
+	case "text":
+		if lyrics == LyricsJoined {
+			return strings.Join(song.Text, coupletSeparator)
+		}
+
+		return song.Text
+	case "explicit":
+		return song.Explicit
+	default:
+		return song.Link
+	}
+}
+
+// SparseSongDTO encodes only the selected fields of a song.
+// The object is assembled by hand to keep the order the fields were selected in.
+type SparseSongDTO struct {
+	song   *SongModel
+	fields []string
+	lyrics string
+}
+
+func (s *SongModel) ToSparseDTO(fields []string) SparseSongDTO {
+	return SparseSongDTO{song: s, fields: fields, lyrics: LyricsArray}
+}
+
+func (d SparseSongDTO) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+
+	b.WriteByte('{')
+	for i, field := range d.fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		encoded, err := json.Marshal(songFieldValue(d.song, field, d.lyrics))
+		if err != nil {
+			return nil, err
+		}
+
+		b.WriteString(strconv.Quote(field))
+		b.WriteByte(':')
+		b.Write(encoded)
+	}
+	b.WriteByte('}')
+
+	return []byte(b.String()), nil
+}
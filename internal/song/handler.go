@@ -3,11 +3,12 @@ package song
 import (
 	"effective-mobile/go/config"
 	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,6 +24,81 @@ func NewSongHandler(cfg *config.Config, service *SongService) *SongHandler {
 	}
 }
 
+// swagger:parameters GetSongs ExportSongs GetStats
+type songFilterDescription struct {
+	// Name of the song
+	// in: query
+	// example: Angel
+	// required: false
+	Song *string `form:"song" json:"song"`
+	// Group of the song
+	// in: query
+	// example: Massive Attack
+	// required: false
+	Group *string `form:"group" json:"group"`
+	// Release date of the song
+	// in: query
+	// example: 2021-01-01
+	// required: false
+	ReleaseDate *time.Time `form:"release_date" time_format:"2006-01-02" json:"release_date"`
+	// Lyrics of the song
+	// in: query
+	// example: Blah-blah-blah
+	// required: false
+	Text *string `form:"text" json:"text"`
+	// Link to the song
+	// in: query
+	// example: https://example.com
+	// required: false
+	Link *string `form:"link" json:"link"`
+	// Language detected in the lyrics
+	// in: query
+	// example: ru
+	// required: false
+	Language *string `form:"language" json:"language"`
+	// Whether the lyrics are explicit, explicit=false leaves explicit songs out
+	// in: query
+	// example: false
+	// required: false
+	Explicit *bool `form:"explicit" json:"explicit"`
+	// Filter expression: predicates separated by ";", all of which must hold.
+	// Operators are ==, !=, >, >=, <, <=, =^ (starts with), =~ (contains) and =in=(a,b);
+	// "!" negates a predicate. Fields are id, song, group, release_date, text, link,
+	// couplets, has_lyrics, has_link, language and explicit.
+	// in: query
+	// example: release_date>=1990-01-01;group=in=(Massive Attack,Portishead);!has_link
+	// required: false
+	Filter string `form:"filter" json:"filter"`
+}
+
+// toFilter parses the filter expression and combines it with the simple filter parameters.
+func (d songFilterDescription) toFilter() (SongFilter, error) {
+	expr, err := common.ParseFilter(d.Filter, SongFilterSchema)
+	if err != nil {
+		return SongFilter{}, err
+	}
+
+	if d.Language != nil {
+		language, err := NormalizeLanguage(*d.Language)
+		if err != nil {
+			return SongFilter{}, err
+		}
+
+		d.Language = &language
+	}
+
+	return SongFilter{
+		Song:        d.Song,
+		Group:       d.Group,
+		ReleaseDate: d.ReleaseDate,
+		Text:        d.Text,
+		Link:        d.Link,
+		Language:    d.Language,
+		Explicit:    d.Explicit,
+		Expr:        expr,
+	}, nil
+}
+
 // swagger:route POST /songs Songs CreateSong
 // Create a new song by providing the group and song name
 //
@@ -50,7 +126,7 @@ func (h *SongHandler) CreateSong(ctx *gin.Context) {
 
 	var req Request
 	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
-		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse("invalid request", err))
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
 		return
 	}
 
@@ -63,10 +139,10 @@ func (h *SongHandler) CreateSong(ctx *gin.Context) {
 	if err := h.service.CreateSong(ctx, song); err != nil {
 		switch err {
 		case ErrServiceUnavailable:
-			ctx.JSON(http.StatusServiceUnavailable, common.FormatErrorResponse("service unavailable, try again later", err))
+			ctx.JSON(http.StatusServiceUnavailable, common.FormatErrorResponse(ctx, "service unavailable, try again later", err))
 		default:
-			log.Error("failed to create song: ", err)
-			ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse("failed to create song", err))
+			logging.FromContext(ctx).Error("failed to create song: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to create song", err))
 		}
 
 		return
@@ -77,6 +153,196 @@ func (h *SongHandler) CreateSong(ctx *gin.Context) {
 	})
 }
 
+// swagger:route POST /songs/import Songs ImportSongs
+// Import songs from a JSONL or CSV stream
+//
+// Every JSONL line and every CSV row (after the header) describes a full song.
+// The response reports the outcome of each row.
+//
+// Consumes:
+// - application/x-ndjson
+// - text/csv
+//
+// responses:
+//
+//	200: ImportResponse
+//	400: ErrorResponse
+//	422: ImportResponse
+//	500: ErrorResponse
+func (h *SongHandler) ImportSongs(ctx *gin.Context) {
+	// swagger:parameters ImportSongs
+	type requestDescription struct {
+		// Format of the stream, detected from Content-Type when omitted
+		// in: query
+		// required: false
+		// enum: jsonl,csv
+		Format string `form:"format" json:"format" binding:"omitempty,oneof=jsonl csv"`
+		// Whether to write all rows or none (atomic) or every valid row (best_effort)
+		// in: query
+		// required: false
+		// enum: atomic,best_effort
+		// default: atomic
+		Mode string `form:"mode,default=atomic" json:"mode" binding:"oneof=atomic best_effort"`
+		// Whether to fill missing song details from the detail API, can be overridden per row
+		// in: query
+		// required: false
+		// default: false
+		Enrich bool `form:"enrich,default=false" json:"enrich"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	if req.Format == "" {
+		switch ctx.ContentType() {
+		case "text/csv":
+			req.Format = ImportFormatCSV
+		case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+			req.Format = ImportFormatJSONL
+		default:
+			ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", ErrUnknownImportFormat))
+			return
+		}
+	}
+
+	reader, err := NewImportReader(req.Format, ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid import stream", err))
+		return
+	}
+
+	report, err := h.service.ImportSongs(ctx, reader, ImportOptions{
+		Mode:   req.Mode,
+		Enrich: req.Enrich,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to import songs: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to import songs", err))
+		return
+	}
+
+	// swagger:response ImportResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string       `json:"message"`
+			Body    ImportReport `json:"body"`
+		}
+	}
+
+	if !report.Committed {
+		ctx.JSON(http.StatusUnprocessableEntity, common.BodyResponse{
+			Message: "songs were not imported, fix the failed rows and retry",
+			Body:    report,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "songs successfully imported",
+		Body:    report,
+	})
+}
+
+// swagger:route GET /songs/export Songs ExportSongs
+// Export songs matching the filters as CSV, JSON or JSONL
+//
+// The export is streamed while it is read from the database, so the response
+// is not limited in size and has no pagination.
+//
+// Produces:
+// - text/csv
+// - application/json
+// - application/x-ndjson
+//
+// responses:
+//
+//	200: ExportResponse
+//	400: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) ExportSongs(ctx *gin.Context) {
+	// swagger:parameters ExportSongs
+	type requestDescription struct {
+		// Format of the export
+		// in: query
+		// required: false
+		// enum: csv,json,jsonl
+		// default: jsonl
+		Format string `form:"format,default=jsonl" json:"format" binding:"oneof=csv json jsonl"`
+		// Comma-separated list of columns to export
+		// in: query
+		// required: false
+		// example: id,song,group
+		Columns string `form:"columns" json:"columns"`
+		// How to export lyrics
+		// in: query
+		// required: false
+		// enum: array,joined,none
+		// default: array
+		Lyrics string `form:"lyrics,default=array" json:"lyrics" binding:"oneof=array joined none"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	var filterDescription songFilterDescription
+	if err := ctx.ShouldBindQuery(&filterDescription); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	filter, err := filterDescription.toFilter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid filter", err))
+		return
+	}
+
+	columns, err := ParseSongFields(req.Columns)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	opts := ExportOptions{
+		Format:  req.Format,
+		Columns: columns,
+		Lyrics:  req.Lyrics,
+	}
+
+	writer, err := NewExportWriter(ctx.Writer, opts)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	// swagger:response ExportResponse
+	type responseDescription struct {
+		// Stream of exported songs in the requested format
+		// in: body
+		Body string
+	}
+
+	ctx.Header("Content-Type", ExportContentType(req.Format))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="songs.%s"`, req.Format))
+	ctx.Status(http.StatusOK)
+
+	// The status is already sent at this point, so a failure can only cut the stream short.
+	exported, err := h.service.ExportSongs(ctx, filter, writer, opts.WithText())
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to export songs after ", exported, " rows: ", err)
+		ctx.Abort()
+		return
+	}
+
+	logging.FromContext(ctx).Debug("songs exported: ", exported)
+}
+
 // swagger:route DELETE /songs/:id Songs DeleteSong
 // Delete a song by providing the song ID
 //
@@ -96,195 +362,1093 @@ func (h *SongHandler) DeleteSong(ctx *gin.Context) {
 
 	var req requestDefinition
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse("invalid song id", err))
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
 		return
 	}
 
 	if err := h.service.DeleteSong(ctx, req.ID); err != nil {
-		log.Error("failed to delete song: ", err)
-		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse("failed to delete song", err))
+		logging.FromContext(ctx).Error("failed to delete song: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to delete song", err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, common.Response{Message: "song successfully deleted"})
 }
 
-// swagger:route GET /songs/:id/lyrics Songs GetSongLyrics
-// Get lyrics for a song
+// swagger:route GET /songs/:id Songs GetSong
+// Get a song by providing the song ID
+//
+// Related data listed in the include parameter is embedded into the song.
 //
 // responses:
 //
-//	200: LyricsResponse
+//	200: SongResponse
 //	400: ErrorResponse
 //	401: ErrorResponse
+//	404: ErrorResponse
 //	500: ErrorResponse
-func (h *SongHandler) GetSongLyrics(ctx *gin.Context) {
-	// swagger:parameters GetSongLyrics
+func (h *SongHandler) GetSong(ctx *gin.Context) {
+	// swagger:parameters GetSong
 	type requestDescription struct {
 		// ID of the song
 		// in: path
 		// required: true
 		ID int `uri:"id" binding:"required" json:"id"`
-		// Page number
-		// in: query
-		// required: false
-		// default: 1
-		Page int `form:"page,default=1" json:"page" binding:"min=1"`
-		// Number of couplets per page
+		// Comma-separated list of related data to embed: lyrics (structure of the lyrics),
+		// links (links to the song and its resources), activity (play and like totals),
+		// languages (languages detected in the lyrics), tags (tags of the song)
+		// and revisions (number of versions of the song)
 		// in: query
 		// required: false
-		// default: 1
-		Limit int `form:"limit,default=1" json:"limit" binding:"min=1,max=10"`
+		// example: lyrics,activity
+		Include string `form:"include" json:"include"`
 	}
 
 	var req requestDescription
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse("invalid song id", err))
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
 		return
 	}
 
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse("invalid query", err))
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
 		return
 	}
 
-	couplets, metadata, err := h.service.GetSongLyrics(ctx, req.ID, req.Page, req.Limit)
+	includes, err := ParseSongIncludes(req.Include)
 	if err != nil {
-		log.Error("failed to get song's lyrics: ", err)
-		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse("failed to get song", err))
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
 		return
 	}
 
-	// swagger:response LyricsResponse
+	song, err := h.service.GetSong(ctx, req.ID, includes)
+	if errors.Is(err, ErrSongNotFound) {
+		ctx.JSON(http.StatusNotFound, common.FormatErrorResponse(ctx, "song not found", err))
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get song: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to get song", err))
+		return
+	}
+
+	// swagger:response SongResponse
 	type responseDescription struct {
 		// in: body
 		Body struct {
-			PaginationMetadata common.PaginationMetadata `json:"metadata"`
-			Message            string                    `json:"message"`
-			Body               []string                  `json:"body"`
+			Message string         `json:"message"`
+			Body    SongDetailsDTO `json:"body"`
 		}
 	}
 
-	ctx.JSON(http.StatusOK, common.PaginationResponse[string](responseDescription{
-		Body: struct {
-			PaginationMetadata common.PaginationMetadata `json:"metadata"`
-			Message            string                    `json:"message"`
-			Body               []string                  `json:"body"`
-		}{
-			Message:            "songs successfully retrieved",
-			PaginationMetadata: *metadata,
-			Body:               couplets,
-		},
-	}.Body))
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "song successfully retrieved",
+		Body:    song.ToDTO(),
+	})
 }
 
-// swagger:route GET /songs Songs GetSongs
-// Get list of songs with optional filters
+// swagger:route PUT /songs/:id/tags Songs ReplaceTags
+// Replace the tags of a song
+//
+// Tags are trimmed and lowercased; repeated tags are stored once. An empty list removes every tag.
 //
 // responses:
 //
-//	200: SongsResponse
+//	200: TagsResponse
 //	400: ErrorResponse
 //	401: ErrorResponse
+//	404: ErrorResponse
 //	500: ErrorResponse
-func (h *SongHandler) GetSongs(ctx *gin.Context) {
-	// swagger:parameters GetSongs
+func (h *SongHandler) ReplaceTags(ctx *gin.Context) {
+	// swagger:parameters ReplaceTags
 	type requestDescription struct {
-		// Page number
-		// in: query
-		// required: false
-		// default: 1
-		Page int `form:"page,default=1" json:"page" binding:"min=1"`
-		// Number of songs per page
-		// in: query
-		// required: false
-		// default: 10
-		Limit int `form:"limit,default=10" json:"limit" binding:"min=1,max=10"`
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// in: body
+		Body struct {
+			// Tags of the song, at most 20 of up to 64 characters each
+			// required: true
+			// example: ["trip-hop","90s"]
+			Tags []string `json:"tags" binding:"required"`
+		}
 	}
 
 	var req requestDescription
-	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse("invalid query", err))
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
 		return
 	}
 
-	// swagger:parameters GetSongs
-	type filterDescription struct {
-		// Name of the song
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	tags, err := h.service.ReplaceTags(ctx, req.ID, req.Body.Tags)
+	switch {
+	case errors.Is(err, ErrInvalidTags):
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid tags", err))
+		return
+	case errors.Is(err, ErrSongNotFound):
+		ctx.JSON(http.StatusNotFound, common.FormatErrorResponse(ctx, "song not found", err))
+		return
+	case err != nil:
+		logging.FromContext(ctx).Error("failed to replace tags: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to replace tags", err))
+		return
+	}
+
+	// swagger:response TagsResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string   `json:"message"`
+			Body    []string `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "tags successfully replaced",
+		Body:    tags,
+	})
+}
+
+// swagger:route GET /songs/:id/lyrics Songs GetSongLyrics
+// Get lyrics for a song
+//
+// Every couplet carries its index and its stable ID, both of which can refer to it
+// in the couplet endpoints.
+//
+// Lyrics are translated to the language given by lang or, failing that, to the one
+// Accept-Language prefers among the translations; Content-Language names the result.
+// With interleave, couplets keep the original text and carry the translation next to it.
+// With mask, words of the profanity lists of the lyrics' languages are replaced with asterisks.
+//
+// responses:
+//
+//	200: LyricsResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) GetSongLyrics(ctx *gin.Context) {
+	// swagger:parameters GetSongLyrics
+	type requestDescription struct {
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// Page number
 		// in: query
-		// example: Angel
 		// required: false
-		Song *string `form:"song" json:"song"`
-		// Group of the song
+		// default: 1
+		Page int `form:"page,default=1" json:"page" binding:"min=1"`
+		// Number of couplets per page
 		// in: query
-		// example: Massive Attack
 		// required: false
-		Group *string `form:"group" json:"group"`
-		// Release date of the song
+		// default: 1
+		Limit int `form:"limit,default=1" json:"limit" binding:"min=1,max=10"`
+		// Language of the translation to return
 		// in: query
-		// example: 2021-01-01
 		// required: false
-		ReleaseDate *time.Time `form:"release_date" time_format:"2006-01-02" json:"release_date"`
-		// Lyrics of the song
+		// example: en
+		Lang string `form:"lang" json:"lang"`
+		// Preferred languages, used when lang is not given
+		// in: header
+		// required: false
+		// example: ru, en;q=0.8
+		AcceptLanguage string `header:"Accept-Language" json:"-"`
+		// Return the original text of couplets along with their translation
 		// in: query
-		// example: Blah-blah-blah
 		// required: false
-		Text *string `form:"text" json:"text"`
-		// Link to the song
+		// default: false
+		Interleave bool `form:"interleave" json:"interleave"`
+		// Replace the letters of profane words with asterisks
 		// in: query
-		// example: https://example.com
 		// required: false
-		Link *string `form:"link" json:"link"`
+		// default: false
+		Mask bool `form:"mask" json:"mask"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
 	}
 
-	var filter filterDescription
-	if err := ctx.ShouldBindQuery(&filter); err != nil {
-		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse("invalid query", err))
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
 		return
 	}
 
-	songs, metadata, err := h.service.GetSongs(ctx, SongFilter(filter), req.Page, req.Limit)
+	language, err := h.service.ResolveLanguage(ctx, req.ID, req.Lang, ctx.GetHeader("Accept-Language"))
 	if err != nil {
-		log.Error("failed to get songs: ", err)
-		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse("failed to get songs", err))
+		h.handleCoupletError(ctx, "failed to get song's lyrics", err)
 		return
 	}
 
-	songsDTO := make([]SongDTO, 0, len(songs))
-	for _, song := range songs {
-		songsDTO = append(songsDTO, SongDTO{
-			ID:          song.ID,
-			Song:        song.Song,
-			Group:       song.Group,
-			ReleaseDate: DateOnly(song.ReleaseDate),
-			Text:        song.Text,
-			Link:        song.Link,
-		})
+	couplets, metadata, err := h.service.GetSongLyrics(ctx, req.ID, req.Page, req.Limit, language, req.Mask)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get song's lyrics: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to get song", err))
+		return
 	}
 
-	// swagger:response SongsResponse
+	coupletsDTO := make([]CoupletDTO, 0, len(couplets))
+	for _, couplet := range couplets {
+		dto := couplet.ToDTO()
+
+		// Untranslated couplets fall back to the original text.
+		if !req.Interleave {
+			if dto.Translation != nil {
+				dto.Text = *dto.Translation
+			}
+
+			dto.Translation = nil
+		}
+
+		coupletsDTO = append(coupletsDTO, dto)
+	}
+
+	if language != "" {
+		ctx.Header("Content-Language", language)
+		ctx.Header("Vary", "Accept-Language")
+	}
+
+	// swagger:response LyricsResponse
 	type responseDescription struct {
 		// in: body
 		Body struct {
 			PaginationMetadata common.PaginationMetadata `json:"metadata"`
 			Message            string                    `json:"message"`
-			Body               []SongDTO                 `json:"body"`
+			Body               []CoupletDTO              `json:"body"`
 		}
 	}
 
-	ctx.JSON(http.StatusOK, common.PaginationResponse[SongDTO](responseDescription{
+	ctx.JSON(http.StatusOK, common.PaginationResponse[CoupletDTO](responseDescription{
 		Body: struct {
 			PaginationMetadata common.PaginationMetadata `json:"metadata"`
 			Message            string                    `json:"message"`
-			Body               []SongDTO                 `json:"body"`
+			Body               []CoupletDTO              `json:"body"`
 		}{
 			Message:            "songs successfully retrieved",
 			PaginationMetadata: *metadata,
-			Body:               songsDTO,
+			Body:               coupletsDTO,
 		},
 	}.Body))
 }
 
+// coupletRefDescription refers to a couplet by its index or, with by=id, by its stable ID.
+//
+// swagger:parameters GetCouplet ReplaceCouplet DeleteCouplet MoveCouplet
+type coupletRefDescription struct {
+	// ID of the song
+	// in: path
+	// required: true
+	ID int `uri:"id" binding:"required" json:"id"`
+	// Zero-based index of the couplet, or its ID when by is id
+	// in: path
+	// required: true
+	Couplet int64 `uri:"couplet" binding:"min=0" json:"couplet"`
+	// How the couplet is referred to
+	// in: query
+	// required: false
+	// enum: index,id
+	// default: index
+	By string `form:"by,default=index" json:"by" binding:"oneof=index id"`
+}
+
+// bindCouplet binds the song ID and the couplet reference, responding with 400 on failure.
+func bindCouplet(ctx *gin.Context) (int, CoupletRef, bool) {
+	var req coupletRefDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid couplet", err))
+		return 0, CoupletRef{}, false
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return 0, CoupletRef{}, false
+	}
+
+	return req.ID, CoupletRef{ByID: req.By == "id", Value: req.Couplet}, true
+}
+
+// swagger:route GET /songs/:id/lyrics/couplets/:couplet Songs GetCouplet
+// Get a couplet of a song
+//
+// responses:
+//
+//	200: CoupletResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) GetCouplet(ctx *gin.Context) {
+	songID, ref, ok := bindCouplet(ctx)
+	if !ok {
+		return
+	}
+
+	couplet, err := h.service.GetCouplet(ctx, songID, ref)
+	if err != nil {
+		h.handleCoupletError(ctx, "failed to get couplet", err)
+		return
+	}
+
+	// swagger:response CoupletResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string     `json:"message"`
+			Body    CoupletDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "couplet successfully retrieved",
+		Body:    couplet.ToDTO(),
+	})
+}
+
+// swagger:route PUT /songs/:id/lyrics/couplets/:couplet Songs ReplaceCouplet
+// Replace the text of a couplet, keeping its ID and position
+//
+// responses:
+//
+//	200: CoupletResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) ReplaceCouplet(ctx *gin.Context) {
+	// swagger:parameters ReplaceCouplet
+	type requestDescription struct {
+		// in: body
+		Body struct {
+			// New text of the couplet
+			// required: true
+			Text string `json:"text" binding:"required"`
+		}
+	}
+
+	songID, ref, ok := bindCouplet(ctx)
+	if !ok {
+		return
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	couplet, err := h.service.ReplaceCouplet(ctx, songID, ref, req.Body.Text)
+	if err != nil {
+		h.handleCoupletError(ctx, "failed to replace couplet", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "couplet successfully replaced",
+		Body:    couplet.ToDTO(),
+	})
+}
+
+// swagger:route POST /songs/:id/lyrics/couplets Songs InsertCouplet
+// Insert a couplet into the lyrics of a song
+//
+// responses:
+//
+//	201: CoupletResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) InsertCouplet(ctx *gin.Context) {
+	// swagger:parameters InsertCouplet
+	type requestDescription struct {
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// in: body
+		Body struct {
+			// Text of the couplet
+			// required: true
+			Text string `json:"text" binding:"required"`
+			// Zero-based index to insert the couplet at, the couplet is appended when omitted
+			// required: false
+			Index *int `json:"index" binding:"omitempty,min=0"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	couplet, err := h.service.InsertCouplet(ctx, req.ID, req.Body.Index, req.Body.Text)
+	if err != nil {
+		h.handleCoupletError(ctx, "failed to insert couplet", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, common.BodyResponse{
+		Message: "couplet successfully inserted",
+		Body:    couplet.ToDTO(),
+	})
+}
+
+// swagger:route DELETE /songs/:id/lyrics/couplets/:couplet Songs DeleteCouplet
+// Delete a couplet from the lyrics of a song
+//
+// responses:
+//
+//	200: Response
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) DeleteCouplet(ctx *gin.Context) {
+	songID, ref, ok := bindCouplet(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteCouplet(ctx, songID, ref); err != nil {
+		h.handleCoupletError(ctx, "failed to delete couplet", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.Response{Message: "couplet successfully deleted"})
+}
+
+// swagger:route POST /songs/:id/lyrics/couplets/:couplet/move Songs MoveCouplet
+// Move a couplet to another position in the lyrics
+//
+// responses:
+//
+//	200: CoupletResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) MoveCouplet(ctx *gin.Context) {
+	// swagger:parameters MoveCouplet
+	type requestDescription struct {
+		// in: body
+		Body struct {
+			// New zero-based index of the couplet
+			// required: true
+			Index *int `json:"index" binding:"required,min=0"`
+		}
+	}
+
+	songID, ref, ok := bindCouplet(ctx)
+	if !ok {
+		return
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	couplet, err := h.service.MoveCouplet(ctx, songID, ref, *req.Body.Index)
+	if err != nil {
+		h.handleCoupletError(ctx, "failed to move couplet", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "couplet successfully moved",
+		Body:    couplet.ToDTO(),
+	})
+}
+
+// swagger:route GET /songs/:id/translations Songs GetTranslations
+// Get the list of translations of a song's lyrics
+//
+// responses:
+//
+//	200: TranslationsResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) GetTranslations(ctx *gin.Context) {
+	// swagger:parameters GetTranslations
+	type requestDescription struct {
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
+	}
+
+	translations, err := h.service.GetTranslations(ctx, req.ID)
+	if err != nil {
+		h.handleCoupletError(ctx, "failed to get translations", err)
+		return
+	}
+
+	translationsDTO := make([]TranslationDTO, 0, len(translations))
+	for _, translation := range translations {
+		translationsDTO = append(translationsDTO, translation.ToDTO())
+	}
+
+	// swagger:response TranslationsResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string           `json:"message"`
+			Body    []TranslationDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "translations successfully retrieved",
+		Body:    translationsDTO,
+	})
+}
+
+// swagger:route PUT /songs/:id/translations/:lang Songs ReplaceTranslation
+// Store the translation of a song's lyrics to a language
+//
+// The translation holds a couplet for every couplet of the lyrics, in the same order;
+// empty couplets are left untranslated. Translations follow their couplets when
+// the lyrics are edited.
+//
+// responses:
+//
+//	200: TranslationResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) ReplaceTranslation(ctx *gin.Context) {
+	// swagger:parameters ReplaceTranslation
+	type requestDescription struct {
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// Language code of the translation
+		// in: path
+		// required: true
+		// example: en
+		Lang string `uri:"lang" binding:"required" json:"lang"`
+		// in: body
+		Body struct {
+			// Translated couplets
+			// required: true
+			Text []string `json:"text" binding:"required"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid translation", err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	translation, err := h.service.ReplaceTranslation(ctx, req.ID, req.Lang, req.Body.Text)
+	if err != nil {
+		h.handleCoupletError(ctx, "failed to store translation", err)
+		return
+	}
+
+	// swagger:response TranslationResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string         `json:"message"`
+			Body    TranslationDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "translation successfully stored",
+		Body:    translation.ToDTO(),
+	})
+}
+
+// swagger:route DELETE /songs/:id/translations/:lang Songs DeleteTranslation
+// Delete the translation of a song's lyrics to a language
+//
+// responses:
+//
+//	200: Response
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) DeleteTranslation(ctx *gin.Context) {
+	// swagger:parameters DeleteTranslation
+	type requestDescription struct {
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// Language code of the translation
+		// in: path
+		// required: true
+		Lang string `uri:"lang" binding:"required" json:"lang"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid translation", err))
+		return
+	}
+
+	if err := h.service.DeleteTranslation(ctx, req.ID, req.Lang); err != nil {
+		h.handleCoupletError(ctx, "failed to delete translation", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.Response{Message: "translation successfully deleted"})
+}
+
+// swagger:route PUT /songs/:id/explicit Songs SetExplicit
+// Mark a song as explicit or not by hand
+//
+// The flag set by hand takes precedence over the one detected from the lyrics until it is cleared.
+//
+// responses:
+//
+//	200: SongResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) SetExplicit(ctx *gin.Context) {
+	// swagger:parameters SetExplicit
+	type requestDescription struct {
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// in: body
+		Body struct {
+			// Whether the song is explicit
+			// required: true
+			Explicit *bool `json:"explicit" binding:"required"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	h.setExplicit(ctx, req.ID, req.Body.Explicit)
+}
+
+// swagger:route DELETE /songs/:id/explicit Songs ClearExplicit
+// Clear the explicit flag set by hand
+//
+// The song is explicit again only when explicit words are detected in its lyrics.
+//
+// responses:
+//
+//	200: SongResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) ClearExplicit(ctx *gin.Context) {
+	// swagger:parameters ClearExplicit
+	type requestDescription struct {
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
+	}
+
+	h.setExplicit(ctx, req.ID, nil)
+}
+
+func (h *SongHandler) setExplicit(ctx *gin.Context, songID int, explicit *bool) {
+	song, err := h.service.SetExplicit(ctx, songID, explicit)
+	if errors.Is(err, ErrSongNotFound) {
+		ctx.JSON(http.StatusNotFound, common.FormatErrorResponse(ctx, "song not found", err))
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to set explicit flag: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to set explicit flag", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "explicit flag successfully set",
+		Body:    (&SongDetails{Song: song}).ToDTO(),
+	})
+}
+
+// swagger:route GET /stats Songs GetStats
+// Get statistics of the library
+//
+// Statistics of the whole library are read from summary tables kept up to date as songs change,
+// while filtered statistics aggregate the matching songs on the fly.
+//
+// responses:
+//
+//	200: StatsResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) GetStats(ctx *gin.Context) {
+	// swagger:parameters GetStats
+	type requestDescription struct {
+		// Number of groups with the most songs to return
+		// in: query
+		// required: false
+		// default: 10
+		Groups int `form:"groups,default=10" json:"groups" binding:"min=0,max=100"`
+		// Period songs added to the library are counted by
+		// in: query
+		// required: false
+		// enum: day,week,month,year
+		// default: month
+		Growth string `form:"growth,default=month" json:"growth" binding:"oneof=day week month year"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	var filterDescription songFilterDescription
+	if err := ctx.ShouldBindQuery(&filterDescription); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	filter, err := filterDescription.toFilter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid filter", err))
+		return
+	}
+
+	stats, err := h.service.GetLibraryStats(ctx, filter, LibraryStatsOptions(req))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get statistics: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to get statistics", err))
+		return
+	}
+
+	// swagger:response StatsResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string          `json:"message"`
+			Body    LibraryStatsDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "statistics successfully retrieved",
+		Body:    stats.ToDTO(),
+	})
+}
+
+// swagger:parameters GetLyricsStats GetGroupLyricsStats
+type statsOptionsDescription struct {
+	// Stop words to leave out of the top words: auto for the languages detected in the lyrics,
+	// none, or a comma-separated list of languages
+	// in: query
+	// required: false
+	// default: auto
+	// example: en,ru
+	StopWords string `form:"stop_words,default=auto" json:"stop_words"`
+	// Number of most frequent words to return
+	// in: query
+	// required: false
+	// default: 10
+	Top int `form:"top,default=10" json:"top" binding:"min=0,max=100"`
+}
+
+// swagger:route GET /songs/:id/lyrics/stats Songs GetLyricsStats
+// Get statistics of a song's lyrics
+//
+// responses:
+//
+//	200: LyricsStatsResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) GetLyricsStats(ctx *gin.Context) {
+	// swagger:parameters GetLyricsStats
+	type requestDescription struct {
+		// ID of the song
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
+		return
+	}
+
+	var opts statsOptionsDescription
+	if err := ctx.ShouldBindQuery(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	stats, err := h.service.GetLyricsStats(ctx, req.ID, StatsOptions(opts))
+	if err != nil {
+		h.handleStatsError(ctx, err)
+		return
+	}
+
+	// swagger:response LyricsStatsResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string         `json:"message"`
+			Body    LyricsStatsDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "lyrics statistics successfully retrieved",
+		Body:    stats.ToDTO(),
+	})
+}
+
+// swagger:route GET /groups/:group/lyrics/stats Songs GetGroupLyricsStats
+// Get statistics over the lyrics of every song of a group
+//
+// Statistics are cached for a while, computed_at tells when they were computed.
+//
+// responses:
+//
+//	200: LyricsStatsResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) GetGroupLyricsStats(ctx *gin.Context) {
+	// swagger:parameters GetGroupLyricsStats
+	type requestDescription struct {
+		// Name of the group, matched case-insensitively
+		// in: path
+		// required: true
+		// example: Massive Attack
+		Group string `uri:"group" binding:"required" json:"group"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid group", err))
+		return
+	}
+
+	var opts statsOptionsDescription
+	if err := ctx.ShouldBindQuery(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	stats, err := h.service.GetGroupLyricsStats(ctx, req.Group, StatsOptions(opts))
+	if err != nil {
+		h.handleStatsError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "lyrics statistics successfully retrieved",
+		Body:    stats.ToDTO(),
+	})
+}
+
+func (h *SongHandler) handleStatsError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidStopWords):
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+	case errors.Is(err, ErrSongNotFound), errors.Is(err, ErrGroupNotFound):
+		ctx.JSON(http.StatusNotFound, common.FormatErrorResponse(ctx, "failed to get lyrics statistics", err))
+	default:
+		logging.FromContext(ctx).Error("failed to get lyrics statistics: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to get lyrics statistics", err))
+	}
+}
+
+// handleCoupletError responds to errors of the lyrics and translation endpoints.
+func (h *SongHandler) handleCoupletError(ctx *gin.Context, message string, err error) {
+	switch err {
+	case ErrInvalidLanguage, ErrMisalignedLyrics:
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, message, err))
+	case ErrSongNotFound, ErrCoupletNotFound, ErrTranslationNotFound:
+		ctx.JSON(http.StatusNotFound, common.FormatErrorResponse(ctx, message, err))
+	default:
+		logging.FromContext(ctx).Error(message, ": ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, message, err))
+	}
+}
+
+// swagger:route GET /songs Songs GetSongs
+// Get list of songs with optional filters
+//
+// Pages can be selected by number or, more efficiently for deep pages, by the
+// next_cursor and prev_cursor tokens returned in the metadata.
+// Songs carry only the fields listed in the fields parameter, and lyrics=none leaves lyrics out.
+//
+// responses:
+//
+//	200: SongsResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	500: ErrorResponse
+func (h *SongHandler) GetSongs(ctx *gin.Context) {
+	// swagger:parameters GetSongs
+	type requestDescription struct {
+		// Page number
+		// in: query
+		// required: false
+		// default: 1
+		Page int `form:"page,default=1" json:"page" binding:"min=1"`
+		// Number of songs per page
+		// in: query
+		// required: false
+		// default: 10
+		Limit int `form:"limit,default=10" json:"limit" binding:"min=1,max=10"`
+		// Cursor returned as next_cursor or prev_cursor, takes precedence over the page number
+		// in: query
+		// required: false
+		Cursor string `form:"cursor" json:"cursor"`
+		// Comma-separated list of fields to sort by, prefixed with "-" for descending order.
+		// Allowed fields are id, song, group and release_date; ties are broken by id.
+		// in: query
+		// required: false
+		// example: -release_date,group,song
+		Sort string `form:"sort" json:"sort"`
+		// Comma-separated list of fields to return; every field is returned by default.
+		// Allowed fields are id, song, group, release_date, text, link and explicit.
+		// in: query
+		// required: false
+		// example: id,song,group
+		Fields string `form:"fields" json:"fields"`
+		// Whether to return lyrics
+		// in: query
+		// required: false
+		// enum: array,none
+		// default: array
+		Lyrics string `form:"lyrics,default=array" json:"lyrics" binding:"oneof=array none"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	fields, err := ParseSongFields(req.Fields)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	if req.Lyrics == LyricsNone {
+		fields = WithoutLyrics(fields)
+	}
+
+	page := SongPage{Page: req.Page, Limit: req.Limit, Sort: req.Sort}
+	if req.Cursor != "" {
+		cursor, err := common.DecodeCursor(req.Cursor)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+			return
+		}
+
+		page.Cursor = cursor
+	}
+
+	var filterDescription songFilterDescription
+	if err := ctx.ShouldBindQuery(&filterDescription); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	filter, err := filterDescription.toFilter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid filter", err))
+		return
+	}
+
+	songs, metadata, err := h.service.GetSongs(ctx, filter, page, fields)
+	if errors.Is(err, common.ErrInvalidCursor) || errors.Is(err, ErrInvalidSort) {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get songs: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to get songs", err))
+		return
+	}
+
+	songsDTO := make([]SparseSongDTO, 0, len(songs))
+	for _, song := range songs {
+		songsDTO = append(songsDTO, song.ToSparseDTO(fields))
+	}
+
+	// swagger:response SongsResponse
+	type responseDescription struct {
+		// Songs with the requested fields only
+		// in: body
+		Body struct {
+			PaginationMetadata common.PaginationMetadata `json:"metadata"`
+			Message            string                    `json:"message"`
+			Body               []SongDTO                 `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.PaginationResponse[SparseSongDTO]{
+		Message:            "songs successfully retrieved",
+		PaginationMetadata: *metadata,
+		Body:               songsDTO,
+	})
+}
+
 // swagger:route PATCH /songs/:id Songs UpdateSong
 // Update a song by providing the song ID and new data
 //
@@ -329,12 +1493,12 @@ func (h *SongHandler) UpdateSong(ctx *gin.Context) {
 
 	var req requestDescription
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse("invalid song id", err))
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid song id", err))
 		return
 	}
 
 	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
-		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse("invalid request", err))
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
 		return
 	}
 
@@ -346,8 +1510,8 @@ func (h *SongHandler) UpdateSong(ctx *gin.Context) {
 		Link:        req.Body.Link,
 		Text:        req.Body.Text,
 	}); err != nil {
-		log.Error("failed to update song: ", err)
-		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse("failed to update song", err))
+		logging.FromContext(ctx).Error("failed to update song: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to update song", err))
 		return
 	}
 
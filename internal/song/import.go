@@ -0,0 +1,235 @@
+package song
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	ImportFormatJSONL = "jsonl"
+	ImportFormatCSV   = "csv"
+)
+
+const (
+	// ImportModeAtomic writes either every row of the import or none of them.
+	ImportModeAtomic = "atomic"
+	// ImportModeBestEffort writes every valid row and reports the failed ones.
+	ImportModeBestEffort = "best_effort"
+)
+
+const (
+	ImportStatusCreated = "created"
+	ImportStatusFailed  = "failed"
+	ImportStatusSkipped = "skipped"
+)
+
+// maxImportLineSize limits a single JSONL line, lyrics included.
+const maxImportLineSize = 4 << 20
+
+type ImportOptions struct {
+	Mode   string
+	Enrich bool
+}
+
+// ImportRecord is a single song of an import stream.
+type ImportRecord struct {
+	Song        string   `json:"song" binding:"required,max=255"`
+	Group       string   `json:"group" binding:"required,max=255"`
+	ReleaseDate string   `json:"release_date" binding:"omitempty,datetime=2006-01-02"`
+	Text        []string `json:"text"`
+	Link        string   `json:"link" binding:"omitempty,url,max=255"`
+	// Enrich overrides the import-wide enrichment option for the row.
+	Enrich *bool `json:"enrich"`
+}
+
+// swagger:model ImportRowResult
+type ImportRowResult struct {
+	Row      int      `json:"row"`
+	Status   string   `json:"status"`
+	ID       int      `json:"id,omitempty"`
+	Enriched bool     `json:"enriched,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// swagger:model ImportReport
+type ImportReport struct {
+	Mode      string            `json:"mode"`
+	Committed bool              `json:"committed"`
+	Total     int               `json:"total"`
+	Created   int               `json:"created"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+// ImportReader yields records of an import stream one by one.
+// Next returns io.EOF once the stream is exhausted and a *RowError
+// for rows that could not be decoded; reading may continue after a RowError.
+type ImportReader interface {
+	Next() (*ImportRecord, error)
+}
+
+type RowError struct {
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+func NewImportReader(format string, r io.Reader) (ImportReader, error) {
+	switch format {
+	case ImportFormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxImportLineSize)
+		return &jsonlReader{scanner: scanner}, nil
+	case ImportFormatCSV:
+		return newCSVReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %q", format)
+	}
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *jsonlReader) Next() (*ImportRecord, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record ImportRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, &RowError{Err: err}
+		}
+
+		return &record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// csvReader reads records with a header row naming the columns. Lyrics are
+// expected in the "text" column with couplets separated by an empty line.
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, required := range []string{"song", "group"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing the %q column", required)
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Next() (*ImportRecord, error) {
+	row, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, &RowError{Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	field := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+
+		return row[i]
+	}
+
+	record := &ImportRecord{
+		Song:        field("song"),
+		Group:       field("group"),
+		ReleaseDate: field("release_date"),
+		Link:        field("link"),
+	}
+
+	if text := field("text"); text != "" {
+		record.Text = strings.Split(text, "\n\n")
+	}
+
+	if enrich := field("enrich"); enrich != "" {
+		value, err := strconv.ParseBool(enrich)
+		if err != nil {
+			return nil, &RowError{Err: fmt.Errorf("invalid enrich value: %w", err)}
+		}
+
+		record.Enrich = &value
+	}
+
+	return record, nil
+}
+
+// toModel validates the record and converts it to a song.
+func (rec *ImportRecord) toModel() (*SongModel, error) {
+	if err := binding.Validator.ValidateStruct(rec); err != nil {
+		return nil, err
+	}
+
+	song := &SongModel{
+		Song:  rec.Song,
+		Group: rec.Group,
+		Text:  rec.Text,
+		Link:  rec.Link,
+	}
+
+	if song.Text == nil {
+		song.Text = make([]string, 0)
+	}
+
+	if rec.ReleaseDate != "" {
+		releaseDate, err := time.Parse(time.DateOnly, rec.ReleaseDate)
+		if err != nil {
+			return nil, err
+		}
+
+		song.ReleaseDate = releaseDate
+	}
+
+	return song, nil
+}
@@ -0,0 +1,56 @@
+package song
+
+import (
+	"effective-mobile/go/pkg/langdetect"
+	"effective-mobile/go/pkg/profanity"
+	"strings"
+)
+
+// undeterminedLanguage marks lyrics whose language could not be detected, so that
+// they are told apart from lyrics not analysed yet.
+const undeterminedLanguage = "und"
+
+// detectLanguages returns the languages of the lyrics, most prominent first.
+func detectLanguages(text []string) []DetectedLanguage {
+	results := langdetect.Detect(strings.Join(text, "\n"))
+	if len(results) == 0 {
+		return []DetectedLanguage{{Language: undeterminedLanguage}}
+	}
+
+	languages := make([]DetectedLanguage, 0, len(results))
+	for _, result := range results {
+		languages = append(languages, DetectedLanguage{Language: result.Language, Confidence: result.Confidence})
+	}
+
+	return languages
+}
+
+// profanityLanguages returns the languages whose profanity lists apply to the lyrics.
+// Lyrics of undetermined language are checked against every list.
+func profanityLanguages(languages []DetectedLanguage) []string {
+	codes := make([]string, 0, len(languages))
+	for _, language := range languages {
+		if language.Language != undeterminedLanguage {
+			codes = append(codes, language.Language)
+		}
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	return codes
+}
+
+// maskCouplets masks listed words of the couplets, in the original by the lists of the detected
+// languages and in the translation by the list of the translation language.
+func maskCouplets(lists *profanity.Lists, couplets []*Couplet, languages []DetectedLanguage, translationLanguage string) {
+	original := profanityLanguages(languages)
+	for _, couplet := range couplets {
+		couplet.Text = lists.Mask(couplet.Text, original)
+		if couplet.Translation != nil {
+			translation := lists.Mask(*couplet.Translation, []string{translationLanguage})
+			couplet.Translation = &translation
+		}
+	}
+}
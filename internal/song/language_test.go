@@ -0,0 +1,128 @@
+package song
+
+import (
+	"effective-mobile/go/config"
+	"effective-mobile/go/pkg/profanity"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectLanguagesFallsBackToUndetermined(t *testing.T) {
+	undetermined := []DetectedLanguage{{Language: undeterminedLanguage}}
+
+	for _, text := range [][]string{nil, {""}, {"la la"}, {"東京 の 夜 は 長い"}} {
+		if got := detectLanguages(text); !reflect.DeepEqual(got, undetermined) {
+			t.Errorf("detectLanguages(%q) = %+v, want %+v", text, got, undetermined)
+		}
+	}
+}
+
+func TestDetectLanguages(t *testing.T) {
+	got := detectLanguages([]string{"I walk the line", "because you are mine"})
+	if len(got) != 1 || got[0].Language != "en" || got[0].Confidence <= 0.5 {
+		t.Errorf("detectLanguages = %+v, want en", got)
+	}
+}
+
+func TestProfanityLanguages(t *testing.T) {
+	tests := []struct {
+		languages []DetectedLanguage
+		want      []string
+	}{
+		{nil, nil},
+		{[]DetectedLanguage{{Language: undeterminedLanguage}}, nil},
+		{[]DetectedLanguage{{Language: "en", Confidence: 0.7}, {Language: "ru", Confidence: 0.3}}, []string{"en", "ru"}},
+	}
+
+	for _, tt := range tests {
+		if got := profanityLanguages(tt.languages); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("profanityLanguages(%+v) = %q, want %q", tt.languages, got, tt.want)
+		}
+	}
+}
+
+func TestMaskCouplets(t *testing.T) {
+	lists := profanity.Default()
+
+	translation := "Какая блядь это сказала"
+	couplets := []*Couplet{
+		{Index: 0, Text: "Oh, bullshit, I said", Translation: &translation},
+		{Index: 1, Text: "Nothing to mask here"},
+	}
+
+	maskCouplets(lists, couplets, []DetectedLanguage{{Language: "en", Confidence: 1}}, "ru")
+
+	if want := "Oh, ********, I said"; couplets[0].Text != want {
+		t.Errorf("original = %q, want %q", couplets[0].Text, want)
+	}
+
+	if want := "Какая ***** это сказала"; *couplets[0].Translation != want {
+		t.Errorf("translation = %q, want %q", *couplets[0].Translation, want)
+	}
+
+	if want := "Какая блядь это сказала"; translation != want {
+		t.Errorf("translation was modified in place to %q", translation)
+	}
+
+	if couplets[1].Translation != nil || couplets[1].Text != "Nothing to mask here" {
+		t.Errorf("untranslated couplet = %+v", couplets[1])
+	}
+}
+
+func TestMaskCoupletsUsesTheTranslationLanguage(t *testing.T) {
+	lists := profanity.Default()
+
+	// An English word in a Russian translation is left alone, while the original
+	// of undetermined language is checked against every list.
+	translation := "bullshit"
+	couplets := []*Couplet{{Text: "bullshit", Translation: &translation}}
+
+	maskCouplets(lists, couplets, []DetectedLanguage{{Language: undeterminedLanguage}}, "ru")
+
+	if couplets[0].Text != "********" {
+		t.Errorf("original = %q, want it masked", couplets[0].Text)
+	}
+
+	if *couplets[0].Translation != "bullshit" {
+		t.Errorf("translation = %q, want it unmasked", *couplets[0].Translation)
+	}
+}
+
+func TestProfanityListsDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.txt"), []byte("darn\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, value := range map[string]string{
+		"SONG_DETAIL_API":     "http://localhost",
+		"DB_HOST":             "localhost",
+		"DB_PORT":             "5432",
+		"DB_NAME":             "test",
+		"DB_USER":             "test",
+		"DB_PASSWORD":         "test",
+		"PROFANITY_LISTS_DIR": dir,
+	} {
+		t.Setenv(name, value)
+	}
+
+	cfg, err := config.ParseConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSongService(cfg, nil)
+	if !s.profanity.Contains("darn", []string{"en"}) {
+		t.Error("list of PROFANITY_LISTS_DIR is not used")
+	}
+
+	if s.profanity.Contains("bullshit", []string{"en"}) {
+		t.Error("built-in English list is not replaced")
+	}
+
+	if !s.profanity.Contains("scheiße", []string{"de"}) {
+		t.Error("built-in German list is not kept")
+	}
+}
@@ -0,0 +1,30 @@
+package song
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcomes of detail API requests.
+const (
+	detailOutcomeOK              = "ok"
+	detailOutcomeTimeout         = "timeout"
+	detailOutcomeError           = "error"
+	detailOutcomeInvalidResponse = "invalid_response"
+	detailOutcomeRejected        = "breaker_open"
+)
+
+var (
+	detailRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "song",
+		Name:      "detail_api_request_duration_seconds",
+		Help:      "Duration of detail API requests by outcome: ok, timeout, error, invalid_response, breaker_open or http_<class>xx.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	enrichmentQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "song",
+		Name:      "enrichment_queue_depth",
+		Help:      "Number of songs waiting for or undergoing enrichment from the detail API.",
+	})
+)
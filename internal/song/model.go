@@ -1,6 +1,10 @@
 package song
 
-import "time"
+import (
+	"effective-mobile/go/internal/common"
+	"slices"
+	"time"
+)
 
 type SongModel struct {
 	ID          int       `db:"id"`
@@ -9,6 +13,135 @@ type SongModel struct {
 	ReleaseDate time.Time `db:"release_date"`
 	Text        []string  `db:"text"`
 	Link        string    `db:"link"`
+	// Explicit tells whether the lyrics are explicit, as detected or as overridden by hand.
+	Explicit bool `db:"explicit"`
+	// ExplicitOverride is the flag set by hand, nil while detection decides.
+	ExplicitOverride *bool `db:"explicit_override"`
+	// Languages are detected from Text and stored along with the song.
+	Languages []DetectedLanguage `db:"-"`
+}
+
+// LyricsAnalysis is the data derived from song lyrics whenever they are written.
+type LyricsAnalysis struct {
+	Languages []DetectedLanguage
+	// Explicit tells whether the lyrics contain words of the profanity lists of their languages.
+	Explicit bool
+}
+
+// LyricsAnalyzer analyses lyrics as they are written.
+type LyricsAnalyzer func(text []string) *LyricsAnalysis
+
+// DetectedLanguage is a language of song lyrics with the share of the lyrics written in it.
+type DetectedLanguage struct {
+	Language   string
+	Confidence float64
+}
+
+// Couplet is a couplet of song lyrics. Its ID stays the same while the couplet is edited or moved.
+type Couplet struct {
+	ID    int64
+	Index int
+	Text  string
+	// Translation is the translated text, nil when the couplet is not translated.
+	Translation *string
+}
+
+// TranslationSummary describes a translation of song lyrics.
+type TranslationSummary struct {
+	Language string
+	// Couplets is the number of translated couplets.
+	Couplets  int
+	UpdatedAt time.Time
+}
+
+// CoupletRef refers to a couplet of a song either by its zero-based index or by its ID.
+type CoupletRef struct {
+	ByID  bool
+	Value int64
+}
+
+// resolve returns the index of the referenced couplet among the couplet IDs of a song.
+func (r CoupletRef) resolve(ids []int64) (int, error) {
+	if !r.ByID {
+		if r.Value < 0 || r.Value >= int64(len(ids)) {
+			return 0, ErrCoupletNotFound
+		}
+
+		return int(r.Value), nil
+	}
+
+	index := slices.Index(ids, r.Value)
+	if index < 0 {
+		return 0, ErrCoupletNotFound
+	}
+
+	return index, nil
+}
+
+// SongDetails is a song along with the related data requested by includes.
+type SongDetails struct {
+	Song     *SongModel
+	Includes []string
+	// Activity is loaded only for IncludeActivity.
+	Activity *SongActivity
+	// Languages are loaded only for IncludeLanguages.
+	Languages []DetectedLanguage
+	// Tags are loaded only for IncludeTags.
+	Tags []string
+	// Revisions is loaded only for IncludeRevisions.
+	Revisions int
+}
+
+type SongActivity struct {
+	Plays int64
+	Likes int64
+}
+
+// SongPage selects a page of songs either by a cursor or by a page number.
+// Sort is a comma-separated list of fields, see parseSongOrder; when it is empty
+// the ordering the cursor was issued for is used.
+type SongPage struct {
+	Page   int
+	Limit  int
+	Sort   string
+	Cursor *common.Cursor
+}
+
+// LibraryStatsOptions shape the lists of LibraryStats.
+type LibraryStatsOptions struct {
+	// Groups is the number of largest groups to report.
+	Groups int
+	// Growth is the period songs added to the library are counted by: day, week, month or year.
+	Growth string
+}
+
+// LibraryStats are aggregates over the songs of the library.
+type LibraryStats struct {
+	Songs         int64
+	Groups        int64
+	MissingLyrics int64
+	MissingLinks  int64
+	// TopGroups are the groups with the most songs.
+	TopGroups []GroupCount
+	// Years counts songs by the year of release, in chronological order.
+	Years []YearCount
+	// Growth counts songs by the period they were added in, in chronological order.
+	Growth []PeriodCount
+}
+
+type GroupCount struct {
+	Group string
+	Songs int64
+}
+
+type YearCount struct {
+	Year  int
+	Songs int64
+}
+
+type PeriodCount struct {
+	Period time.Time
+	Songs  int64
 }
 
 type SongFilter struct {
@@ -17,4 +150,26 @@ type SongFilter struct {
 	ReleaseDate *time.Time
 	Text        *string
 	Link        *string
+	// Language matches songs with lyrics detected to be partly or fully in the language.
+	Language *string
+	Explicit *bool
+	// Expr holds predicates of the filter expression, see common.ParseFilter.
+	Expr common.FilterExpr
+}
+
+// IsEmpty reports whether the filter matches every song.
+func (f SongFilter) IsEmpty() bool {
+	return f.Song == nil && f.Group == nil && f.ReleaseDate == nil && f.Text == nil &&
+		f.Link == nil && f.Language == nil && f.Explicit == nil && len(f.Expr) == 0
+}
+
+// QualityReport counts songs with missing or suspicious data.
+type QualityReport struct {
+	TotalSongs         int `json:"total_songs"`
+	MissingLyrics      int `json:"missing_lyrics"`
+	EmptyCouplets      int `json:"empty_couplets"`
+	MissingLink        int `json:"missing_link"`
+	InvalidLink        int `json:"invalid_link"`
+	DefaultReleaseDate int `json:"default_release_date"`
+	DuplicateSongs     int `json:"duplicate_songs"`
 }
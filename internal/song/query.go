@@ -0,0 +1,446 @@
+package song
+
+import (
+	"effective-mobile/go/internal/common"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// songFilterCondition matches songs of the "s" alias against the simple filter
+// parameters, bound to $1-$5 by songFilterWhere.
+const songFilterCondition = `
+	($1::text IS NULL OR LOWER(s.song) LIKE $1) AND
+	($2::text IS NULL OR LOWER(s."group") LIKE $2) AND
+	($3::date IS NULL OR s.release_date = $3) AND
+	($4::text IS NULL OR EXISTS (SELECT 1 FROM unnest(s."text") AS couplet WHERE LOWER(couplet) LIKE $4)) AND
+	($5::text IS NULL OR LOWER(s.link) LIKE $5)
+`
+
+// SongFilterSchema lists the fields of filter expressions over songs.
+var SongFilterSchema = common.FilterSchema{
+	"id":           common.FilterInt,
+	"song":         common.FilterText,
+	"group":        common.FilterText,
+	"release_date": common.FilterDate,
+	"text":         common.FilterText,
+	"link":         common.FilterText,
+	"couplets":     common.FilterInt,
+	"has_lyrics":   common.FilterBool,
+	"has_link":     common.FilterBool,
+	"language":     common.FilterText,
+	"explicit":     common.FilterBool,
+}
+
+// filterColumns maps fields of SongFilterSchema to SQL expressions. Lyrics are
+// matched couplet by couplet and languages one by one, see filterSubqueries.
+var filterColumns = map[string]string{
+	"id":           "s.id",
+	"song":         "s.song",
+	"group":        `s."group"`,
+	"release_date": "s.release_date",
+	"link":         "s.link",
+	"couplets":     `cardinality(s."text")`,
+	"has_lyrics":   `cardinality(s."text") > 0`,
+	"has_link":     "s.link <> ''",
+	"explicit":     "s.explicit",
+}
+
+// filterSubquery selects the values of a field with many values per song.
+// A predicate over the field holds when it holds for any of the values.
+type filterSubquery struct {
+	query  string
+	column string
+}
+
+var filterSubqueries = map[string]filterSubquery{
+	"text": {
+		query:  `SELECT 1 FROM unnest(s."text") AS couplet WHERE %s`,
+		column: "couplet",
+	},
+	"language": {
+		query:  `SELECT 1 FROM ` + languagesTable + ` l WHERE l.song_id = s.id AND %s`,
+		column: "l.language",
+	},
+}
+
+// songFilterWhere returns the WHERE condition of the filter together with its parameters.
+// Parameters appended by the caller are to be numbered from len(args)+1.
+func songFilterWhere(filter SongFilter) (string, []interface{}, error) {
+	if filter.Text != nil {
+		var text = "%" + strings.Trim(*filter.Text, " %") + "%"
+		filter.Text = &text
+	}
+
+	args := []interface{}{
+		filter.Song,
+		filter.Group,
+		filter.ReleaseDate,
+		filter.Text,
+		filter.Link,
+	}
+
+	conditions := []string{songFilterCondition}
+	if filter.Language != nil {
+		args = append(args, *filter.Language)
+		conditions = append(conditions, fmt.Sprintf(
+			`EXISTS (SELECT 1 FROM %s l WHERE l.song_id = s.id AND l.language = $%d)`, languagesTable, len(args),
+		))
+	}
+
+	if filter.Explicit != nil {
+		args = append(args, *filter.Explicit)
+		conditions = append(conditions, fmt.Sprintf(`s.explicit = $%d`, len(args)))
+	}
+
+	for _, predicate := range filter.Expr {
+		condition, err := compilePredicate(predicate, &args)
+		if err != nil {
+			return "", nil, err
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// compilePredicate turns the predicate into SQL, appending its values to args.
+// Text is compared case-insensitively.
+func compilePredicate(predicate common.FilterPredicate, args *[]interface{}) (string, error) {
+	param := func(value interface{}) string {
+		*args = append(*args, value)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+
+	column, ok := filterColumns[predicate.Field]
+
+	subquery, many := filterSubqueries[predicate.Field]
+	if many {
+		column, ok = subquery.column, true
+	}
+
+	if !ok {
+		return "", fmt.Errorf("%w: unknown field %q", common.ErrInvalidFilter, predicate.Field)
+	}
+
+	if predicate.Type == common.FilterText {
+		column = "LOWER(" + column + ")"
+	}
+
+	cast := map[common.FilterType]string{
+		common.FilterText: "text",
+		common.FilterDate: "date",
+		common.FilterInt:  "int",
+	}[predicate.Type]
+
+	value := predicate.Values[0]
+	if s, ok := value.(string); ok {
+		value = strings.ToLower(s)
+	}
+
+	var condition string
+	switch predicate.Op {
+	case common.FilterOpEq, common.FilterOpNe:
+		if predicate.Type == common.FilterBool {
+			condition = column
+			if value != true {
+				condition = "NOT " + column
+			}
+		} else {
+			condition = fmt.Sprintf("%s = %s::%s", column, param(value), cast)
+		}
+
+		// Inequality is the negated equality, which for lyrics and languages means that none of the values matches.
+		if predicate.Op == common.FilterOpNe {
+			predicate.Negate = !predicate.Negate
+		}
+	case common.FilterOpGt, common.FilterOpGe, common.FilterOpLt, common.FilterOpLe:
+		condition = fmt.Sprintf("%s %s %s::%s", column, predicate.Op, param(value), cast)
+	case common.FilterOpPrefix:
+		condition = fmt.Sprintf("%s LIKE %s", column, param(escapeLike(value.(string))+"%"))
+	case common.FilterOpContains:
+		condition = fmt.Sprintf("%s LIKE %s", column, param("%"+escapeLike(value.(string))+"%"))
+	case common.FilterOpIn:
+		values := make([]interface{}, 0, len(predicate.Values))
+		for _, v := range predicate.Values {
+			if s, ok := v.(string); ok {
+				v = strings.ToLower(s)
+			}
+
+			values = append(values, v)
+		}
+
+		condition = fmt.Sprintf("%s = ANY(%s::%s[])", column, param(values), cast)
+	default:
+		return "", fmt.Errorf("%w: unknown operator %q", common.ErrInvalidFilter, predicate.Op)
+	}
+
+	if many {
+		condition = "EXISTS (" + fmt.Sprintf(subquery.query, condition) + ")"
+	}
+
+	if predicate.Negate {
+		return "NOT (" + condition + ")", nil
+	}
+
+	return "(" + condition + ")", nil
+}
+
+// escapeLike escapes the wildcards of LIKE patterns.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// libraryStatsQueries select the parts of LibraryStats. The groups query takes the number
+// of groups and the growth query the period as the parameters following args.
+type libraryStatsQueries struct {
+	totals string
+	groups string
+	years  string
+	growth string
+	args   []interface{}
+}
+
+// newLibraryStatsQueries reads the summary tables when the filter matches every song,
+// and aggregates the matching songs otherwise.
+func newLibraryStatsQueries(filter SongFilter) (*libraryStatsQueries, error) {
+	if filter.IsEmpty() {
+		return &libraryStatsQueries{
+			totals: fmt.Sprintf(`
+				SELECT
+					COALESCE(SUM(songs), 0)::bigint,
+					(SELECT COUNT(*) FROM %s),
+					COALESCE(SUM(missing_lyrics), 0)::bigint,
+					COALESCE(SUM(missing_links), 0)::bigint
+				FROM %s
+			`, statsGroupsTable, statsTotalsTable),
+			groups: fmt.Sprintf(`
+				SELECT "group", songs FROM %s
+				ORDER BY songs DESC, "group"
+				LIMIT $1
+			`, statsGroupsTable),
+			years: fmt.Sprintf(`SELECT year, songs FROM %s ORDER BY year`, statsYearsTable),
+			growth: fmt.Sprintf(`
+				SELECT date_trunc($1::text, day)::date, SUM(songs)::bigint
+				FROM %s
+				GROUP BY 1
+				HAVING SUM(songs) <> 0
+				ORDER BY 1
+			`, statsDaysTable),
+		}, nil
+	}
+
+	where, args, err := songFilterWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	next := len(args) + 1
+	return &libraryStatsQueries{
+		totals: fmt.Sprintf(`
+			SELECT
+				COUNT(*),
+				COUNT(DISTINCT s."group"),
+				COUNT(*) FILTER (WHERE cardinality(s."text") = 0),
+				COUNT(*) FILTER (WHERE s.link = '')
+			FROM %s s
+			WHERE %s
+		`, songsTable, where),
+		groups: fmt.Sprintf(`
+			SELECT s."group", COUNT(*) FROM %s s
+			WHERE %s
+			GROUP BY 1
+			ORDER BY 2 DESC, 1
+			LIMIT $%d
+		`, songsTable, where, next),
+		years: fmt.Sprintf(`
+			SELECT EXTRACT(YEAR FROM s.release_date)::int, COUNT(*) FROM %s s
+			WHERE %s
+			GROUP BY 1
+			ORDER BY 1
+		`, songsTable, where),
+		growth: fmt.Sprintf(`
+			SELECT date_trunc($%d::text, s.created_at AT TIME ZONE 'UTC')::date, COUNT(*) FROM %s s
+			WHERE %s
+			GROUP BY 1
+			ORDER BY 1
+		`, next, songsTable, where),
+		args: args,
+	}, nil
+}
+
+// sortField describes a column songs can be ordered by.
+type sortField struct {
+	column string
+	// cast is the SQL type cursor values of the field are converted to.
+	cast  string
+	value func(song *SongModel) string
+}
+
+var sortFields = map[string]sortField{
+	"id": {
+		column: "s.id",
+		cast:   "int",
+		value:  func(song *SongModel) string { return strconv.Itoa(song.ID) },
+	},
+	"song": {
+		column: "s.song",
+		cast:   "text",
+		value:  func(song *SongModel) string { return song.Song },
+	},
+	"group": {
+		column: `s."group"`,
+		cast:   "text",
+		value:  func(song *SongModel) string { return song.Group },
+	},
+	"release_date": {
+		column: "s.release_date",
+		cast:   "date",
+		value:  func(song *SongModel) string { return song.ReleaseDate.Format(time.DateOnly) },
+	},
+}
+
+type sortKey struct {
+	name  string
+	field sortField
+	desc  bool
+}
+
+// songOrder is an ordering of songs that is total, i.e. it ends with a unique key,
+// so that keyset pagination neither skips nor repeats rows.
+type songOrder []sortKey
+
+var defaultSongOrder = songOrder{{name: "id", field: sortFields["id"]}}
+
+// parseSongOrder parses a comma-separated list of sortable fields, each optionally
+// prefixed with "-" for descending order. The ID is appended as the final tie-breaker
+// unless the list already contains it.
+func parseSongOrder(sort string) (songOrder, error) {
+	if strings.TrimSpace(sort) == "" {
+		return defaultSongOrder, nil
+	}
+
+	names := strings.Split(sort, ",")
+	order := make(songOrder, 0, len(sortFields))
+	seen := make(map[string]bool, len(sortFields))
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := sortFields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, name)
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %q is repeated", ErrInvalidSort, name)
+		}
+
+		seen[name] = true
+		order = append(order, sortKey{name: name, field: field, desc: desc})
+
+		// Nothing can follow a unique key, the order is already total.
+		if name == "id" {
+			if i != len(names)-1 {
+				return nil, fmt.Errorf("%w: no field can follow \"id\"", ErrInvalidSort)
+			}
+
+			return order, nil
+		}
+	}
+
+	return append(order, sortKey{name: "id", field: sortFields["id"]}), nil
+}
+
+// String returns the ordering in the format of the sort parameter.
+func (o songOrder) String() string {
+	names := make([]string, 0, len(o))
+	for _, key := range o {
+		if key.desc {
+			names = append(names, "-"+key.name)
+		} else {
+			names = append(names, key.name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// orderBy returns the ORDER BY list, inverted for pages read backwards.
+func (o songOrder) orderBy(reverse bool) string {
+	columns := make([]string, 0, len(o))
+	for _, key := range o {
+		if key.desc != reverse {
+			columns = append(columns, key.field.column+" DESC")
+		} else {
+			columns = append(columns, key.field.column+" ASC")
+		}
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// cursor returns a cursor pointing at the song.
+func (o songOrder) cursor(song *SongModel, backward bool) string {
+	values := make([]string, 0, len(o))
+	for _, key := range o {
+		values = append(values, key.field.value(song))
+	}
+
+	return common.Cursor{Sort: o.String(), Values: values, Backward: backward}.Encode()
+}
+
+// keysetCondition returns the condition selecting the rows after the cursor,
+// or before it for backward cursors. Its parameters are numbered starting from argN.
+func (o songOrder) keysetCondition(cursor *common.Cursor, argN int) (string, []interface{}, error) {
+	if cursor.Sort != o.String() || len(cursor.Values) != len(o) {
+		return "", nil, common.ErrInvalidCursor
+	}
+
+	args := make([]interface{}, 0, len(o))
+	params := make([]string, 0, len(o))
+	for i, key := range o {
+		args = append(args, cursor.Values[i])
+		params = append(params, fmt.Sprintf("$%d::text::%s", argN+i, key.field.cast))
+	}
+
+	op := func(key sortKey) string {
+		if key.desc != cursor.Backward {
+			return "<"
+		}
+
+		return ">"
+	}
+
+	// A row comparison can use a composite index, but only applies when all keys share the direction.
+	uniform := true
+	for _, key := range o {
+		uniform = uniform && key.desc == o[0].desc
+	}
+
+	if uniform {
+		columns := make([]string, 0, len(o))
+		for _, key := range o {
+			columns = append(columns, key.field.column)
+		}
+
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), op(o[0]), strings.Join(params, ", ")), args, nil
+	}
+
+	alternatives := make([]string, 0, len(o))
+	for i, key := range o {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf("%s = %s", o[j].field.column, params[j]))
+		}
+
+		terms = append(terms, fmt.Sprintf("%s %s %s", key.field.column, op(key), params[i]))
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
@@ -0,0 +1,331 @@
+package song
+
+import (
+	"effective-mobile/go/internal/common"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSongOrder(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+		err  error
+	}{
+		{sort: "", want: "id"},
+		{sort: "  ", want: "id"},
+		{sort: "id", want: "id"},
+		{sort: "-id", want: "-id"},
+		{sort: "song", want: "song,id"},
+		{sort: "-release_date, song", want: "-release_date,song,id"},
+		{sort: "group,-id", want: "group,-id"},
+		{sort: "title", err: ErrInvalidSort},
+		{sort: "song,-song", err: ErrInvalidSort},
+		{sort: "id,song", err: ErrInvalidSort},
+		{sort: "song,", err: ErrInvalidSort},
+	}
+
+	for _, tt := range tests {
+		order, err := parseSongOrder(tt.sort)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%q: got error %v, want %v", tt.sort, err, tt.err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: %v", tt.sort, err)
+			continue
+		}
+
+		if got := order.String(); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		values   []string
+		backward bool
+		want     string
+	}{
+		{
+			name:   "ascending",
+			sort:   "song",
+			values: []string{"Angel", "3"},
+			want:   `(s.song, s.id) > ($4::text::text, $5::text::int)`,
+		},
+		{
+			name:     "ascending backward",
+			sort:     "song",
+			values:   []string{"Angel", "3"},
+			backward: true,
+			want:     `(s.song, s.id) < ($4::text::text, $5::text::int)`,
+		},
+		{
+			name:   "descending",
+			sort:   "-id",
+			values: []string{"3"},
+			want:   `(s.id) < ($4::text::int)`,
+		},
+		{
+			name:   "mixed",
+			sort:   "-release_date,group",
+			values: []string{"1998-04-20", "Massive Attack", "3"},
+			want: `((s.release_date < $4::text::date) OR ` +
+				`(s.release_date = $4::text::date AND s."group" > $5::text::text) OR ` +
+				`(s.release_date = $4::text::date AND s."group" = $5::text::text AND s.id > $6::text::int))`,
+		},
+		{
+			name:     "mixed backward",
+			sort:     "-release_date,group",
+			values:   []string{"1998-04-20", "Massive Attack", "3"},
+			backward: true,
+			want: `((s.release_date > $4::text::date) OR ` +
+				`(s.release_date = $4::text::date AND s."group" < $5::text::text) OR ` +
+				`(s.release_date = $4::text::date AND s."group" = $5::text::text AND s.id < $6::text::int))`,
+		},
+	}
+
+	for _, tt := range tests {
+		order, err := parseSongOrder(tt.sort)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		cursor := &common.Cursor{Sort: order.String(), Values: tt.values, Backward: tt.backward}
+		condition, args, err := order.keysetCondition(cursor, 4)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if condition != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, condition, tt.want)
+		}
+
+		want := make([]interface{}, 0, len(tt.values))
+		for _, value := range tt.values {
+			want = append(want, value)
+		}
+
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("%s: got args %v, want %v", tt.name, args, want)
+		}
+	}
+}
+
+func TestKeysetConditionRejectsMismatchedCursors(t *testing.T) {
+	order, err := parseSongOrder("-release_date")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cursors := map[string]*common.Cursor{
+		"other sort":     {Sort: "release_date,id", Values: []string{"1998-04-20", "3"}},
+		"missing values": {Sort: order.String(), Values: []string{"1998-04-20"}},
+		"extra values":   {Sort: order.String(), Values: []string{"1998-04-20", "3", "4"}},
+	}
+
+	for name, cursor := range cursors {
+		if _, _, err := order.keysetCondition(cursor, 1); !errors.Is(err, common.ErrInvalidCursor) {
+			t.Errorf("%s: got %v, want %v", name, err, common.ErrInvalidCursor)
+		}
+	}
+}
+
+func TestSongOrderCursorRoundTrip(t *testing.T) {
+	order, err := parseSongOrder("-release_date,group")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	song := &SongModel{
+		ID:          3,
+		Group:       "Massive Attack",
+		ReleaseDate: time.Date(1998, time.April, 20, 0, 0, 0, 0, time.UTC),
+	}
+
+	cursor, err := common.DecodeCursor(order.cursor(song, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := common.Cursor{Sort: "-release_date,group,id", Values: []string{"1998-04-20", "Massive Attack", "3"}, Backward: true}
+	if !reflect.DeepEqual(*cursor, want) {
+		t.Fatalf("got %+v, want %+v", *cursor, want)
+	}
+
+	if _, _, err := order.keysetCondition(cursor, 1); err != nil {
+		t.Fatalf("cursor issued for the order is rejected: %v", err)
+	}
+}
+
+func TestCompilePredicate(t *testing.T) {
+	date := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		predicate common.FilterPredicate
+		want      string
+		args      []interface{}
+	}{
+		{
+			name:      "text equality is case-insensitive",
+			predicate: common.FilterPredicate{Field: "song", Type: common.FilterText, Op: common.FilterOpEq, Values: []interface{}{"Angel"}},
+			want:      `(LOWER(s.song) = $1::text)`,
+			args:      []interface{}{"angel"},
+		},
+		{
+			name:      "inequality negates equality",
+			predicate: common.FilterPredicate{Field: "group", Type: common.FilterText, Op: common.FilterOpNe, Values: []interface{}{"Portishead"}},
+			want:      `NOT (LOWER(s."group") = $1::text)`,
+			args:      []interface{}{"portishead"},
+		},
+		{
+			name:      "negated inequality",
+			predicate: common.FilterPredicate{Field: "group", Type: common.FilterText, Op: common.FilterOpNe, Values: []interface{}{"Portishead"}, Negate: true},
+			want:      `(LOWER(s."group") = $1::text)`,
+			args:      []interface{}{"portishead"},
+		},
+		{
+			name:      "comparison",
+			predicate: common.FilterPredicate{Field: "release_date", Type: common.FilterDate, Op: common.FilterOpGe, Values: []interface{}{date}},
+			want:      `(s.release_date >= $1::date)`,
+			args:      []interface{}{date},
+		},
+		{
+			name:      "negated comparison",
+			predicate: common.FilterPredicate{Field: "couplets", Type: common.FilterInt, Op: common.FilterOpLt, Values: []interface{}{3}, Negate: true},
+			want:      `NOT (cardinality(s."text") < $1::int)`,
+			args:      []interface{}{3},
+		},
+		{
+			name:      "prefix escapes wildcards",
+			predicate: common.FilterPredicate{Field: "song", Type: common.FilterText, Op: common.FilterOpPrefix, Values: []interface{}{`100%_Pure\`}},
+			want:      `(LOWER(s.song) LIKE $1)`,
+			args:      []interface{}{`100\%\_pure\\%`},
+		},
+		{
+			name:      "contains escapes wildcards",
+			predicate: common.FilterPredicate{Field: "link", Type: common.FilterText, Op: common.FilterOpContains, Values: []interface{}{"a_b"}},
+			want:      `(LOWER(s.link) LIKE $1)`,
+			args:      []interface{}{`%a\_b%`},
+		},
+		{
+			name:      "in list",
+			predicate: common.FilterPredicate{Field: "group", Type: common.FilterText, Op: common.FilterOpIn, Values: []interface{}{"Massive Attack", "Portishead"}},
+			want:      `(LOWER(s."group") = ANY($1::text[]))`,
+			args:      []interface{}{[]interface{}{"massive attack", "portishead"}},
+		},
+		{
+			name:      "int in list",
+			predicate: common.FilterPredicate{Field: "id", Type: common.FilterInt, Op: common.FilterOpIn, Values: []interface{}{1, 2}},
+			want:      `(s.id = ANY($1::int[]))`,
+			args:      []interface{}{[]interface{}{1, 2}},
+		},
+		{
+			name:      "bool",
+			predicate: common.FilterPredicate{Field: "has_link", Type: common.FilterBool, Op: common.FilterOpEq, Values: []interface{}{true}},
+			want:      `(s.link <> '')`,
+		},
+		{
+			name:      "false bool",
+			predicate: common.FilterPredicate{Field: "explicit", Type: common.FilterBool, Op: common.FilterOpEq, Values: []interface{}{false}},
+			want:      `(NOT s.explicit)`,
+		},
+		{
+			name:      "negated bool",
+			predicate: common.FilterPredicate{Field: "has_lyrics", Type: common.FilterBool, Op: common.FilterOpEq, Values: []interface{}{true}, Negate: true},
+			want:      `NOT (cardinality(s."text") > 0)`,
+		},
+		{
+			name:      "lyrics match any couplet",
+			predicate: common.FilterPredicate{Field: "text", Type: common.FilterText, Op: common.FilterOpContains, Values: []interface{}{"love"}},
+			want:      `(EXISTS (SELECT 1 FROM unnest(s."text") AS couplet WHERE LOWER(couplet) LIKE $1))`,
+			args:      []interface{}{"%love%"},
+		},
+		{
+			name:      "language inequality means no language matches",
+			predicate: common.FilterPredicate{Field: "language", Type: common.FilterText, Op: common.FilterOpNe, Values: []interface{}{"ru"}},
+			want:      `NOT (EXISTS (SELECT 1 FROM song_languages l WHERE l.song_id = s.id AND LOWER(l.language) = $1::text))`,
+			args:      []interface{}{"ru"},
+		},
+	}
+
+	for _, tt := range tests {
+		var args []interface{}
+		got, err := compilePredicate(tt.predicate, &args)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: got args %#v, want %#v", tt.name, args, tt.args)
+		}
+	}
+}
+
+func TestCompilePredicateNumbersParametersAfterExistingOnes(t *testing.T) {
+	args := []interface{}{"a", "b"}
+	predicate := common.FilterPredicate{Field: "song", Type: common.FilterText, Op: common.FilterOpEq, Values: []interface{}{"c"}}
+
+	got, err := compilePredicate(predicate, &args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `(LOWER(s.song) = $3::text)`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestCompilePredicateRejectsUnknownFieldsAndOperators(t *testing.T) {
+	predicates := map[string]common.FilterPredicate{
+		"unknown field":    {Field: "title", Type: common.FilterText, Op: common.FilterOpEq, Values: []interface{}{"a"}},
+		"unknown operator": {Field: "song", Type: common.FilterText, Op: "~~", Values: []interface{}{"a"}},
+	}
+
+	for name, predicate := range predicates {
+		var args []interface{}
+		if _, err := compilePredicate(predicate, &args); !errors.Is(err, common.ErrInvalidFilter) {
+			t.Errorf("%s: got %v, want %v", name, err, common.ErrInvalidFilter)
+		}
+	}
+}
+
+func TestParsedFilterCompiles(t *testing.T) {
+	expr, err := common.ParseFilter(`release_date>=1990-01-01;group=in=(Massive Attack,"Portishead");!has_link`, SongFilterSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	where, args, err := songFilterWhere(SongFilter{Expr: expr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := songFilterCondition +
+		` AND (s.release_date >= $6::date)` +
+		` AND (LOWER(s."group") = ANY($7::text[]))` +
+		` AND NOT (s.link <> '')`
+	if where != want {
+		t.Errorf("got\n%s\nwant\n%s", where, want)
+	}
+
+	if len(args) != 7 {
+		t.Errorf("got %d args, want 7", len(args))
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"effective-mobile/go/config"
 	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/tracing"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type SongRepository struct {
@@ -17,7 +21,20 @@ type SongRepository struct {
 	db     *pgxpool.Pool
 }
 
-const songsTable = "songs"
+const (
+	songsTable          = "songs"
+	activityTotalsTable = "song_activity_totals"
+	tagsTable           = "song_tags"
+	translationsTable   = "song_translations"
+	languagesTable      = "song_languages"
+	statsTotalsTable    = "song_stats_totals"
+	statsGroupsTable    = "song_stats_groups"
+	statsYearsTable     = "song_stats_years"
+	statsDaysTable      = "song_stats_days"
+)
+
+// exportFetchSize is the number of rows fetched from the export cursor at once.
+const exportFetchSize = 500
 
 func NewSongRepository(cfg *config.Config, db *pgxpool.Pool) *SongRepository {
 	return &SongRepository{
@@ -27,32 +44,307 @@ func NewSongRepository(cfg *config.Config, db *pgxpool.Pool) *SongRepository {
 }
 
 func (r *SongRepository) CreateSong(ctx context.Context, song *SongModel) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.CreateSong")
+	defer span.End()
+
 	query := fmt.Sprintf(`
-		INSERT INTO %s (song, "group", release_date, "text", link) 
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO %s (song, "group", release_date, "text", link, explicit_detected) 
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
 	`, songsTable)
-	err := r.db.QueryRow(ctx, query, song.Song, song.Group, song.ReleaseDate, song.Text, song.Link).Scan(&song.ID)
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, song.Song, song.Group, song.ReleaseDate, song.Text, song.Link, song.Explicit).Scan(&song.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := r.insertLanguages(ctx, tx, song.ID, song.Languages); err != nil {
+			return err
+		}
+
+		return r.writeSongEvents(ctx, tx, EventSongCreated, []*SongModel{song})
+	})
 	if err != nil {
 		return err
 	}
 
-	log.Debug("song created with ID: ", song.ID)
+	logging.FromContext(ctx).Debug("song created with ID: ", song.ID)
 	return nil
 }
 
-func (r *SongRepository) DeleteSong(ctx context.Context, songID int) error {
-	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, songsTable)
-	_, err := r.db.Exec(context.Background(), query, songID)
+func (r *SongRepository) GetSong(ctx context.Context, songID int) (*SongModel, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetSong")
+	defer span.End()
+
+	return r.getSong(ctx, r.db, songID)
+}
+
+// querier runs queries on the pool or within a transaction.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func (r *SongRepository) getSong(ctx context.Context, q querier, songID int) (*SongModel, error) {
+	query := fmt.Sprintf(`
+		SELECT id, song, "group", release_date, "text", link, explicit, explicit_override
+		FROM %s
+		WHERE id = $1
+	`, songsTable)
+
+	var song SongModel
+	err := q.QueryRow(ctx, query, songID).Scan(
+		&song.ID,
+		&song.Song,
+		&song.Group,
+		&song.ReleaseDate,
+		&song.Text,
+		&song.Link,
+		&song.Explicit,
+		&song.ExplicitOverride,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSongNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &song, nil
+}
+
+// GetSongActivity returns the play and like totals of the song, which are zero until it is first played or liked.
+func (r *SongRepository) GetSongActivity(ctx context.Context, songID int) (*SongActivity, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetSongActivity")
+	defer span.End()
+
+	query := fmt.Sprintf(`
+		SELECT plays, likes
+		FROM %s
+		WHERE song_id = $1
+	`, activityTotalsTable)
+
+	var activity SongActivity
+	err := r.db.QueryRow(ctx, query, songID).Scan(&activity.Plays, &activity.Likes)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	return &activity, nil
+}
+
+// GetSongTags returns the tags of the song in alphabetical order.
+func (r *SongRepository) GetSongTags(ctx context.Context, songID int) ([]string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetSongTags")
+	defer span.End()
+
+	query := fmt.Sprintf(`SELECT tag FROM %s WHERE song_id = $1 ORDER BY tag`, tagsTable)
+
+	rows, err := r.db.Query(ctx, query, songID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// ReplaceSongTags replaces every tag of the song.
+func (r *SongRepository) ReplaceSongTags(ctx context.Context, songID int, tags []string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.ReplaceSongTags")
+	defer span.End()
+
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := fmt.Sprintf(`SELECT id FROM %s WHERE id = $1 FOR UPDATE`, songsTable)
+		if err := tx.QueryRow(ctx, query, songID).Scan(&songID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrSongNotFound
+			}
+
+			return err
+		}
+
+		query = fmt.Sprintf(`DELETE FROM %s WHERE song_id = $1`, tagsTable)
+		if _, err := tx.Exec(ctx, query, songID); err != nil {
+			return err
+		}
+
+		query = fmt.Sprintf(`INSERT INTO %s (song_id, tag) SELECT $1, unnest($2::text[])`, tagsTable)
+		if _, err := tx.Exec(ctx, query, songID, tags); err != nil {
+			return err
+		}
+
+		return r.writeSongEvent(ctx, tx, EventSongUpdated, songID)
+	})
 	if err != nil {
 		return err
 	}
 
-	log.Debug("song deleted with ID: ", songID)
+	logging.FromContext(ctx).Debug("tags replaced for song with ID: ", songID)
 	return nil
 }
 
-func (r *SongRepository) UpdateSong(ctx context.Context, dto UpdateSongDTO) error {
+// GetSongRevision returns the number of versions of the song, counted by the database as it changes.
+func (r *SongRepository) GetSongRevision(ctx context.Context, songID int) (int, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetSongRevision")
+	defer span.End()
+
+	query := fmt.Sprintf(`SELECT revision FROM %s WHERE id = $1`, songsTable)
+
+	var revision int
+	err := r.db.QueryRow(ctx, query, songID).Scan(&revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrSongNotFound
+	}
+
+	return revision, err
+}
+
+// GetSongIDs returns IDs of all songs, or only of the ones lacking lyrics or a link.
+func (r *SongRepository) GetSongIDs(ctx context.Context, onlyIncomplete bool) ([]int, error) {
+	query := fmt.Sprintf(`
+		SELECT id FROM %s
+		WHERE NOT $1 OR cardinality("text") = 0 OR link = ''
+		ORDER BY id
+	`, songsTable)
+
+	rows, err := r.db.Query(ctx, query, onlyIncomplete)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *SongRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return r.db.Begin(ctx)
+}
+
+// CopySongs writes the songs with a single COPY. IDs are reserved from the
+// sequence beforehand, since COPY cannot return the generated values.
+func (r *SongRepository) CopySongs(ctx context.Context, tx pgx.Tx, songs []*SongModel) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.CopySongs")
+	defer span.End()
+
+	query := fmt.Sprintf(`
+		SELECT nextval(pg_get_serial_sequence('%s', 'id'))
+		FROM generate_series(1, $1)
+	`, songsTable)
+
+	rows, err := tx.Query(ctx, query, len(songs))
+	if err != nil {
+		return err
+	}
+
+	for i := 0; rows.Next(); i++ {
+		if err := rows.Scan(&songs[i].ID); err != nil {
+			rows.Close()
+			return err
+		}
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{songsTable},
+		[]string{"id", "song", "group", "release_date", "text", "link", "explicit_detected"},
+		pgx.CopyFromSlice(len(songs), func(i int) ([]interface{}, error) {
+			song := songs[i]
+			return []interface{}{song.ID, song.Song, song.Group, song.ReleaseDate, song.Text, song.Link, song.Explicit}, nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	var languages [][]interface{}
+	for _, song := range songs {
+		for _, language := range song.Languages {
+			languages = append(languages, []interface{}{song.ID, language.Language, language.Confidence})
+		}
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{languagesTable},
+		[]string{"song_id", "language", "confidence"},
+		pgx.CopyFromRows(languages),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := r.writeSongEvents(ctx, tx, EventSongCreated, songs); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("songs copied: ", len(songs))
+	return nil
+}
+
+// InsertSongs writes the songs with CopySongs in a transaction of its own.
+func (r *SongRepository) InsertSongs(ctx context.Context, songs []*SongModel) error {
+	return r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return r.CopySongs(ctx, tx, songs)
+	})
+}
+
+// DeleteSong deletes the song, reporting whether it existed.
+func (r *SongRepository) DeleteSong(ctx context.Context, songID int) (bool, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.DeleteSong")
+	defer span.End()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, songsTable)
+
+	deleted := false
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, songID)
+		if err != nil {
+			return err
+		}
+
+		if deleted = tag.RowsAffected() > 0; !deleted {
+			return nil
+		}
+
+		return r.writeSongEvent(ctx, tx, EventSongDeleted, songID)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	logging.FromContext(ctx).Debug("song deleted with ID: ", songID)
+	return deleted, nil
+}
+
+// UpdateSong updates the given fields of the song, storing the analysis of the new lyrics
+// if there is one, and records the change as an event of the given type.
+func (r *SongRepository) UpdateSong(ctx context.Context, dto UpdateSongDTO, analysis *LyricsAnalysis, eventType string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.UpdateSong")
+	defer span.End()
+
 	query := fmt.Sprintf(`
         UPDATE %s SET 
             song = COALESCE($1, song), 
@@ -63,171 +355,995 @@ func (r *SongRepository) UpdateSong(ctx context.Context, dto UpdateSongDTO) erro
         WHERE id = $6
     `, songsTable)
 
-	_, err := r.db.Exec(context.Background(), query,
-		dto.Song,
-		dto.Group,
-		dto.ReleaseDate,
-		dto.Text,
-		dto.Link,
-		dto.SongID,
-	)
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query,
+			dto.Song,
+			dto.Group,
+			dto.ReleaseDate,
+			dto.Text,
+			dto.Link,
+			dto.SongID,
+		)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
 
+		if analysis != nil {
+			if _, err := r.replaceLyricsAnalysis(ctx, tx, dto.SongID, analysis); err != nil {
+				return err
+			}
+		}
+
+		return r.writeSongEvent(ctx, tx, eventType, dto.SongID)
+	})
 	if err != nil {
 		return err
 	}
 
-	log.Debug("song updated with ID: ", dto.SongID)
+	logging.FromContext(ctx).Debug("song updated with ID: ", dto.SongID)
 	return nil
 }
 
-func (r *SongRepository) GetSongs(ctx context.Context, filter SongFilter, page, limit int) ([]*SongModel, *common.PaginationMetadata, error) {
-	page = max(1, page)
-	limit = min(10, max(1, limit))
+// ReplaceLyricsAnalysis stores the analysis of the song's lyrics in place of the previous one.
+// Languages are not part of song events, so an event is only recorded when the explicit flag changes.
+func (r *SongRepository) ReplaceLyricsAnalysis(ctx context.Context, songID int, analysis *LyricsAnalysis) error {
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		changed, err := r.replaceLyricsAnalysis(ctx, tx, songID, analysis)
+		if err != nil || !changed {
+			return err
+		}
 
-	if filter.Text != nil {
-		var text = "%" + strings.Trim(*filter.Text, " %") + "%"
-		filter.Text = &text
+		return r.writeSongEvent(ctx, tx, EventSongUpdated, songID)
+	})
+	if err != nil {
+		return err
 	}
 
-	totalQuery := fmt.Sprintf(`
-		SELECT COUNT(*) FROM (
-			SELECT DISTINCT ON (id)
-				id, 
-				song, 
-				"group", 
-				release_date, 
-				"text", 
-				link,
-				couplet
-			FROM %s s
-			JOIN LATERAL unnest(coalesce(nullif(s.text,'{}'),array[null::text])) AS couplet ON true
-			WHERE 
-				($1::text IS NULL OR LOWER(song) LIKE $1) AND
-				($2::text IS NULL OR LOWER("group") LIKE $2) AND
-				($3::date IS NULL OR release_date = $3) AND
-				($4::text IS NULL OR LOWER(couplet) LIKE $4) AND
-				($5::text IS NULL OR LOWER(link) LIKE $5)
-		) as filtered
+	logging.FromContext(ctx).Debug("lyrics analysed for song with ID: ", songID)
+	return nil
+}
+
+// replaceLyricsAnalysis stores the analysis within the transaction, reporting whether
+// the explicit flag of the song has changed.
+func (r *SongRepository) replaceLyricsAnalysis(ctx context.Context, tx pgx.Tx, songID int, analysis *LyricsAnalysis) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %[1]s s SET explicit_detected = $2
+		FROM (SELECT explicit FROM %[1]s WHERE id = $1 FOR UPDATE) old
+		WHERE s.id = $1
+		RETURNING s.explicit <> old.explicit
 	`, songsTable)
 
+	var changed bool
+	err := tx.QueryRow(ctx, query, songID, analysis.Explicit).Scan(&changed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrSongNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE song_id = $1`, languagesTable)
+	if _, err := tx.Exec(ctx, query, songID); err != nil {
+		return false, err
+	}
+
+	return changed, r.insertLanguages(ctx, tx, songID, analysis.Languages)
+}
+
+// reanalyzeLyrics analyses the lyrics of the song as the transaction sees them and stores the result.
+func (r *SongRepository) reanalyzeLyrics(ctx context.Context, tx pgx.Tx, songID int, analyze LyricsAnalyzer) error {
+	query := fmt.Sprintf(`SELECT "text" FROM %s WHERE id = $1`, songsTable)
+
+	var text []string
+	if err := tx.QueryRow(ctx, query, songID).Scan(&text); err != nil {
+		return err
+	}
+
+	_, err := r.replaceLyricsAnalysis(ctx, tx, songID, analyze(text))
+	return err
+}
+
+// SetExplicitOverride sets the explicit flag of the song by hand, or hands it back to detection when override is nil.
+func (r *SongRepository) SetExplicitOverride(ctx context.Context, songID int, override *bool) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.SetExplicitOverride")
+	defer span.End()
+
+	query := fmt.Sprintf(`UPDATE %s SET explicit_override = $2 WHERE id = $1`, songsTable)
+
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, songID, override)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return ErrSongNotFound
+		}
+
+		return r.writeSongEvent(ctx, tx, EventSongUpdated, songID)
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("explicit flag overridden for song with ID: ", songID)
+	return nil
+}
+
+func (r *SongRepository) insertLanguages(ctx context.Context, tx pgx.Tx, songID int, languages []DetectedLanguage) error {
+	codes := make([]string, 0, len(languages))
+	confidences := make([]float64, 0, len(languages))
+	for _, language := range languages {
+		codes = append(codes, language.Language)
+		confidences = append(confidences, language.Confidence)
+	}
+
 	query := fmt.Sprintf(`
-		SELECT DISTINCT ON (id)
-			id, 
-			song, 
-			"group", 
-			release_date, 
-			"text", 
-			link,
-			couplet
-		FROM %s s
-		JOIN LATERAL unnest(coalesce(nullif(s.text,'{}'),array[null::text])) AS couplet ON true
-		WHERE 
-			($1::text IS NULL OR LOWER(song) LIKE $1) AND
-			($2::text IS NULL OR LOWER("group") LIKE $2) AND
-			($3::date IS NULL OR release_date = $3) AND
-			($4::text IS NULL OR LOWER(couplet) LIKE $4) AND
-			($5::text IS NULL OR LOWER(link) LIKE $5)
-        LIMIT $6 OFFSET $7
-    `, songsTable)
+		INSERT INTO %s (song_id, language, confidence)
+		SELECT $1, l.language, l.confidence
+		FROM unnest($2::text[], $3::real[]) AS l(language, confidence)
+	`, languagesTable)
 
-	var totalCount int
-	var songs []*SongModel
+	_, err := tx.Exec(ctx, query, songID, codes, confidences)
+	return err
+}
+
+// GetSongLanguages returns the languages detected in the song's lyrics, most prominent first.
+func (r *SongRepository) GetSongLanguages(ctx context.Context, songID int) ([]DetectedLanguage, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetSongLanguages")
+	defer span.End()
+
+	query := fmt.Sprintf(`
+		SELECT language, confidence
+		FROM %s
+		WHERE song_id = $1
+		ORDER BY confidence DESC, language
+	`, languagesTable)
+
+	rows, err := r.db.Query(ctx, query, songID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	languages := make([]DetectedLanguage, 0)
+	for rows.Next() {
+		var language DetectedLanguage
+		if err := rows.Scan(&language.Language, &language.Confidence); err != nil {
+			return nil, err
+		}
+
+		languages = append(languages, language)
+	}
+
+	return languages, rows.Err()
+}
 
-	err := r.db.QueryRow(ctx, totalQuery,
-		filter.Song,
-		filter.Group,
-		filter.ReleaseDate,
-		filter.Text,
-		filter.Link,
-	).Scan(&totalCount)
+// GetGroupLyrics returns the lyrics of every song of the group, whose name is matched case-insensitively.
+func (r *SongRepository) GetGroupLyrics(ctx context.Context, group string) ([][]string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetGroupLyrics")
+	defer span.End()
 
+	query := fmt.Sprintf(`
+		SELECT "text" FROM %s
+		WHERE LOWER("group") = LOWER($1)
+		ORDER BY id
+	`, songsTable)
+
+	rows, err := r.db.Query(ctx, query, group)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lyrics [][]string
+	for rows.Next() {
+		var text []string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+
+		lyrics = append(lyrics, text)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(lyrics) == 0 {
+		return nil, ErrGroupNotFound
+	}
+
+	return lyrics, nil
+}
+
+// GetUndetectedSongIDs returns IDs of songs whose languages have never been detected.
+func (r *SongRepository) GetUndetectedSongIDs(ctx context.Context) ([]int, error) {
+	query := fmt.Sprintf(`
+		SELECT s.id FROM %s s
+		WHERE NOT EXISTS (SELECT 1 FROM %s l WHERE l.song_id = s.id)
+		ORDER BY s.id
+	`, songsTable, languagesTable)
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetSongs returns a page of songs matching the filter. Pages are selected either by
+// a cursor, which avoids counting and scanning skipped rows, or by a page number.
+// Both modes report cursors to the neighbouring pages. Only the given fields are loaded,
+// along with the fields the songs are ordered by.
+func (r *SongRepository) GetSongs(ctx context.Context, filter SongFilter, page SongPage, fields []string) ([]*SongModel, *common.PaginationMetadata, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetSongs")
+	defer span.End()
+
+	page.Page = max(1, page.Page)
+	limit := min(10, max(1, page.Limit))
+
+	sort := page.Sort
+	if sort == "" && page.Cursor != nil {
+		sort = page.Cursor.Sort
+	}
+
+	order, err := parseSongOrder(sort)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	metadata := common.CalculateMetadata(totalCount, page, limit)
+	where, args, err := songFilterWhere(filter)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	rows, err := r.db.Query(context.Background(), query,
-		filter.Song,
-		filter.Group,
-		filter.ReleaseDate,
-		filter.Text,
-		filter.Link,
-		limit, max(0, page-1)*limit,
-	)
+	backward := page.Cursor != nil && page.Cursor.Backward
+
+	var metadata common.PaginationMetadata
+	if page.Cursor != nil {
+		condition, cursorArgs, err := order.keysetCondition(page.Cursor, len(args)+1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		where += " AND " + condition
+		args = append(args, cursorArgs...)
+		metadata = common.PaginationMetadata{PageSize: limit}
+	} else {
+		totalQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s s WHERE %s`, songsTable, where)
+
+		var totalCount int
+		if err := r.db.QueryRow(ctx, totalQuery, args...).Scan(&totalCount); err != nil {
+			return nil, nil, err
+		}
+
+		metadata = common.CalculateMetadata(totalCount, page.Page, limit)
+	}
+
+	// Cursors are built from the sort keys, so they are loaded even when not requested.
+	selected := make(map[string]bool, len(SongFields))
+	for _, field := range fields {
+		selected[field] = true
+	}
+	for _, key := range order {
+		selected[key.name] = true
+	}
 
+	var projection []string
+	for _, field := range SongFields {
+		if selected[field] {
+			column, _ := songColumn(field, &SongModel{})
+			projection = append(projection, column)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s s
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, strings.Join(projection, ", "), songsTable, where, order.orderBy(backward), len(args)+1, len(args)+2)
+
+	offset := 0
+	if page.Cursor == nil {
+		offset = (page.Page - 1) * limit
+	}
+
+	// One extra row tells whether there is a page past this one.
+	rows, err := r.db.Query(ctx, query, append(args, limit+1, offset)...)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer rows.Close()
 
+	var songs []*SongModel
 	for rows.Next() {
 		var song SongModel
-		err = rows.Scan(
-			&song.ID,
-			&song.Song,
-			&song.Group,
-			&song.ReleaseDate,
-			&song.Text,
-			&song.Link,
-			nil,
-		)
 
-		if err != nil {
+		dest := make([]interface{}, 0, len(projection))
+		for _, field := range SongFields {
+			if selected[field] {
+				_, d := songColumn(field, &song)
+				dest = append(dest, d)
+			}
+		}
+
+		if err := rows.Scan(dest...); err != nil {
 			return nil, nil, err
 		}
 
 		songs = append(songs, &song)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := len(songs) > limit
+	if hasMore {
+		songs = songs[:limit]
+	}
+
+	if backward {
+		slices.Reverse(songs)
+	}
+
+	if len(songs) > 0 {
+		first, last := songs[0], songs[len(songs)-1]
+
+		// Going backwards, the extra row lies before the page and the cursor's row after it.
+		hasNext, hasPrev := hasMore, page.Cursor != nil || page.Page > 1
+		if backward {
+			hasNext, hasPrev = true, hasMore
+		}
+
+		if hasNext {
+			metadata.NextCursor = order.cursor(last, false)
+		}
+
+		if hasPrev {
+			metadata.PrevCursor = order.cursor(first, true)
+		}
+	}
+
 	return songs, &metadata, nil
 }
 
-func (r *SongRepository) GetSongLyrics(ctx context.Context, songID int, page, limit int) ([]string, *common.PaginationMetadata, error) {
+// GetSongLyrics returns a page of couplets. When a language is given, couplets carry their
+// translation to it, if any.
+func (r *SongRepository) GetSongLyrics(ctx context.Context, songID int, page, limit int, language string) ([]*Couplet, *common.PaginationMetadata, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetSongLyrics")
+	defer span.End()
+
 	page = max(1, page)
 	limit = min(10, max(1, limit))
 
 	totalQuery := fmt.Sprintf(`
-		SELECT COUNT(*) FROM (
-			SELECT 
-				unnest(text)
-			FROM %s 
-			WHERE id = $1
-		) as couplets
+		SELECT cardinality("text") FROM %s WHERE id = $1
 	`, songsTable)
 
 	query := fmt.Sprintf(`
-		SELECT 
-			unnest(text)
-		FROM %s 
-		WHERE id = $1
-        LIMIT $2 OFFSET $3
-	`, songsTable)
+		SELECT c.id, c.index - 1, c."text", t."text"
+		FROM %s s
+		CROSS JOIN unnest(s.couplet_ids, s."text") WITH ORDINALITY AS c(id, "text", index)
+		LEFT JOIN %s t ON t.song_id = s.id AND t.language = $4 AND t.couplet_id = c.id
+		WHERE s.id = $1
+		ORDER BY c.index
+		LIMIT $2 OFFSET $3
+	`, songsTable, translationsTable)
 
 	var totalCount int
-	var couplets []string
-
-	if err := r.db.QueryRow(ctx, totalQuery, songID).Scan(&totalCount); err != nil {
+	err := r.db.QueryRow(ctx, totalQuery, songID).Scan(&totalCount)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil, err
 	}
 
 	metadata := common.CalculateMetadata(totalCount, page, limit)
 
-	rows, err := r.db.Query(context.Background(), query, songID, limit, max(0, page-1)*limit)
+	rows, err := r.db.Query(ctx, query, songID, limit, max(0, page-1)*limit, language)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer rows.Close()
 
+	var couplets []*Couplet
 	for rows.Next() {
-		var couplet string
-		err = rows.Scan(&couplet)
-		if err != nil {
+		var couplet Couplet
+		if err := rows.Scan(&couplet.ID, &couplet.Index, &couplet.Text, &couplet.Translation); err != nil {
 			return nil, nil, err
 		}
 
-		couplets = append(couplets, couplet)
+		couplets = append(couplets, &couplet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
 	}
 
 	return couplets, &metadata, nil
+}
+
+// GetCouplet returns the referenced couplet of the song.
+func (r *SongRepository) GetCouplet(ctx context.Context, songID int, ref CoupletRef) (*Couplet, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetCouplet")
+	defer span.End()
+
+	query := fmt.Sprintf(`SELECT couplet_ids, "text" FROM %s WHERE id = $1`, songsTable)
+
+	var ids []int64
+	var text []string
+	err := r.db.QueryRow(ctx, query, songID).Scan(&ids, &text)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSongNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := ref.resolve(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Couplet{ID: ids[index], Index: index, Text: text[index]}, nil
+}
+
+// lockLyrics locks the song against concurrent edits and returns the IDs of its couplets.
+func (r *SongRepository) lockLyrics(ctx context.Context, tx pgx.Tx, songID int) ([]int64, error) {
+	query := fmt.Sprintf(`SELECT couplet_ids FROM %s WHERE id = $1 FOR UPDATE`, songsTable)
+
+	var ids []int64
+	err := tx.QueryRow(ctx, query, songID).Scan(&ids)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSongNotFound
+	}
+
+	return ids, err
+}
+
+// editedLyrics completes an edit of the song's lyrics within its transaction.
+func (r *SongRepository) editedLyrics(ctx context.Context, tx pgx.Tx, songID int, analyze LyricsAnalyzer) error {
+	if err := r.reanalyzeLyrics(ctx, tx, songID, analyze); err != nil {
+		return err
+	}
+
+	return r.writeSongEvent(ctx, tx, EventSongUpdated, songID)
+}
+
+// ReplaceCouplet replaces the text of the referenced couplet, keeping its ID and position,
+// and analyses the new lyrics with analyze.
+func (r *SongRepository) ReplaceCouplet(ctx context.Context, songID int, ref CoupletRef, text string, analyze LyricsAnalyzer) (*Couplet, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.ReplaceCouplet")
+	defer span.End()
 
+	var couplet *Couplet
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		ids, err := r.lockLyrics(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+
+		index, err := ref.resolve(ids)
+		if err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf(`UPDATE %s SET "text"[$2] = $3 WHERE id = $1`, songsTable)
+		if _, err := tx.Exec(ctx, query, songID, index+1, text); err != nil {
+			return err
+		}
+
+		couplet = &Couplet{ID: ids[index], Index: index, Text: text}
+		return r.editedLyrics(ctx, tx, songID, analyze)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("couplet ", couplet.ID, " replaced in song with ID: ", songID)
+	return couplet, nil
+}
+
+// InsertCouplet inserts a couplet at the index, which is clamped to the lyrics,
+// or appends it when the index is nil, and analyses the new lyrics with analyze.
+func (r *SongRepository) InsertCouplet(ctx context.Context, songID int, index *int, text string, analyze LyricsAnalyzer) (*Couplet, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.InsertCouplet")
+	defer span.End()
+
+	var couplet *Couplet
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		ids, err := r.lockLyrics(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+
+		couplet = &Couplet{Index: len(ids), Text: text}
+		if index != nil {
+			couplet.Index = min(len(ids), max(0, *index))
+		}
+
+		query := fmt.Sprintf(`
+			UPDATE %s SET
+				"text" = "text"[:$2] || $3::text || "text"[$2 + 1:],
+				couplet_ids = couplet_ids[:$2] || nextval('song_couplet_id_seq') || couplet_ids[$2 + 1:]
+			WHERE id = $1
+			RETURNING couplet_ids[$2 + 1]
+		`, songsTable)
+
+		if err := tx.QueryRow(ctx, query, songID, couplet.Index, text).Scan(&couplet.ID); err != nil {
+			return err
+		}
+
+		return r.editedLyrics(ctx, tx, songID, analyze)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("couplet ", couplet.ID, " inserted into song with ID: ", songID)
+	return couplet, nil
+}
+
+// DeleteCouplet removes the referenced couplet, shifting the following ones up,
+// and analyses the remaining lyrics with analyze.
+func (r *SongRepository) DeleteCouplet(ctx context.Context, songID int, ref CoupletRef, analyze LyricsAnalyzer) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.DeleteCouplet")
+	defer span.End()
+
+	var id int64
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		ids, err := r.lockLyrics(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+
+		index, err := ref.resolve(ids)
+		if err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf(`
+			UPDATE %s SET
+				"text" = "text"[:$2] || "text"[$2 + 2:],
+				couplet_ids = couplet_ids[:$2] || couplet_ids[$2 + 2:]
+			WHERE id = $1
+		`, songsTable)
+		if _, err := tx.Exec(ctx, query, songID, index); err != nil {
+			return err
+		}
+
+		id = ids[index]
+
+		query = fmt.Sprintf(`DELETE FROM %s WHERE song_id = $1 AND couplet_id = $2`, translationsTable)
+		if _, err := tx.Exec(ctx, query, songID, id); err != nil {
+			return err
+		}
+
+		return r.editedLyrics(ctx, tx, songID, analyze)
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("couplet ", id, " deleted from song with ID: ", songID)
+	return nil
+}
+
+// MoveCouplet moves the referenced couplet to the index, which is clamped to the lyrics.
+func (r *SongRepository) MoveCouplet(ctx context.Context, songID int, ref CoupletRef, index int) (*Couplet, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.MoveCouplet")
+	defer span.End()
+
+	var couplet *Couplet
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		ids, err := r.lockLyrics(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+
+		from, err := ref.resolve(ids)
+		if err != nil {
+			return err
+		}
+
+		couplet = &Couplet{ID: ids[from], Index: min(len(ids)-1, max(0, index))}
+
+		// The couplet is cut out of the lyrics first and then inserted at the new index.
+		query := fmt.Sprintf(`
+			UPDATE %[1]s s SET
+				"text" = r."text"[:$3] || s."text"[$2 + 1] || r."text"[$3 + 1:],
+				couplet_ids = r.couplet_ids[:$3] || s.couplet_ids[$2 + 1] || r.couplet_ids[$3 + 1:]
+			FROM (
+				SELECT
+					"text"[:$2] || "text"[$2 + 2:] AS "text",
+					couplet_ids[:$2] || couplet_ids[$2 + 2:] AS couplet_ids
+				FROM %[1]s
+				WHERE id = $1
+			) r
+			WHERE s.id = $1
+			RETURNING s."text"[$3 + 1]
+		`, songsTable)
+
+		if err := tx.QueryRow(ctx, query, songID, from, couplet.Index).Scan(&couplet.Text); err != nil {
+			return err
+		}
+
+		// Moving a couplet leaves the words, and so the analysis, as they were.
+		return r.writeSongEvent(ctx, tx, EventSongUpdated, songID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("couplet ", couplet.ID, " moved to ", couplet.Index, " in song with ID: ", songID)
+	return couplet, nil
+}
+
+// GetTranslations returns the translations of the song's lyrics ordered by language.
+// Only couplets still present in the lyrics are counted.
+func (r *SongRepository) GetTranslations(ctx context.Context, songID int) ([]*TranslationSummary, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetTranslations")
+	defer span.End()
+
+	query := fmt.Sprintf(`
+		SELECT t.language, COUNT(*), MAX(t.updated_at)
+		FROM %s t
+		JOIN %s s ON s.id = t.song_id AND t.couplet_id = ANY(s.couplet_ids)
+		WHERE t.song_id = $1
+		GROUP BY t.language
+		ORDER BY t.language
+	`, translationsTable, songsTable)
+
+	rows, err := r.db.Query(ctx, query, songID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var translations []*TranslationSummary
+	for rows.Next() {
+		var translation TranslationSummary
+		if err := rows.Scan(&translation.Language, &translation.Couplets, &translation.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		translations = append(translations, &translation)
+	}
+
+	return translations, rows.Err()
+}
+
+// ReplaceTranslation stores the translation of the lyrics, one couplet per couplet of the original.
+// Empty couplets are left untranslated.
+func (r *SongRepository) ReplaceTranslation(ctx context.Context, songID int, language string, text []string) (*TranslationSummary, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.ReplaceTranslation")
+	defer span.End()
+
+	translation := &TranslationSummary{Language: language}
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		ids, err := r.lockLyrics(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) != len(text) {
+			return ErrMisalignedLyrics
+		}
+
+		query := fmt.Sprintf(`DELETE FROM %s WHERE song_id = $1 AND language = $2`, translationsTable)
+		if _, err := tx.Exec(ctx, query, songID, language); err != nil {
+			return err
+		}
+
+		query = fmt.Sprintf(`
+			INSERT INTO %s (song_id, language, couplet_id, "text")
+			SELECT $1, $2, c.id, c."text"
+			FROM unnest($3::bigint[], $4::text[]) AS c(id, "text")
+			WHERE c."text" <> ''
+			RETURNING updated_at
+		`, translationsTable)
+
+		rows, err := tx.Query(ctx, query, songID, language, ids, text)
+		if err != nil {
+			return err
+		}
+
+		translation.UpdatedAt = time.Now()
+		for rows.Next() {
+			if err := rows.Scan(&translation.UpdatedAt); err != nil {
+				rows.Close()
+				return err
+			}
+
+			translation.Couplets++
+		}
+
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return r.writeSongEvent(ctx, tx, EventSongUpdated, songID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("translation to ", language, " stored for song with ID: ", songID)
+	return translation, nil
+}
+
+func (r *SongRepository) DeleteTranslation(ctx context.Context, songID int, language string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.DeleteTranslation")
+	defer span.End()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE song_id = $1 AND language = $2`, translationsTable)
+
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := r.lockLyrics(ctx, tx, songID); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, query, songID, language)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return ErrTranslationNotFound
+		}
+
+		return r.writeSongEvent(ctx, tx, EventSongUpdated, songID)
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("translation to ", language, " deleted for song with ID: ", songID)
+	return nil
+}
+
+// GetLibraryStats aggregates the songs matching the filter in a single snapshot.
+func (r *SongRepository) GetLibraryStats(ctx context.Context, filter SongFilter, opts LibraryStatsOptions) (*LibraryStats, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.GetLibraryStats")
+	defer span.End()
+
+	queries, err := newLibraryStatsQueries(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	args := queries.args
+	stats := &LibraryStats{}
+
+	txOptions := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err = r.db.BeginTxFunc(ctx, txOptions, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, queries.totals, args...).Scan(
+			&stats.Songs,
+			&stats.Groups,
+			&stats.MissingLyrics,
+			&stats.MissingLinks,
+		)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, queries.groups, append(args, opts.Groups)...)
+		if err != nil {
+			return err
+		}
+
+		stats.TopGroups = make([]GroupCount, 0)
+		for rows.Next() {
+			var group GroupCount
+			if err := rows.Scan(&group.Group, &group.Songs); err != nil {
+				rows.Close()
+				return err
+			}
+
+			stats.TopGroups = append(stats.TopGroups, group)
+		}
+
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, queries.years, args...)
+		if err != nil {
+			return err
+		}
+
+		stats.Years = make([]YearCount, 0)
+		for rows.Next() {
+			var year YearCount
+			if err := rows.Scan(&year.Year, &year.Songs); err != nil {
+				rows.Close()
+				return err
+			}
+
+			stats.Years = append(stats.Years, year)
+		}
+
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, queries.growth, append(args, opts.Growth)...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		stats.Growth = make([]PeriodCount, 0)
+		for rows.Next() {
+			var period PeriodCount
+			if err := rows.Scan(&period.Period, &period.Songs); err != nil {
+				return err
+			}
+
+			stats.Growth = append(stats.Growth, period)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// ExportSongs streams songs matching the filter to fn through a server-side cursor,
+// so the result set is never loaded into memory as a whole. Lyrics are only
+// selected when withText is set.
+func (r *SongRepository) ExportSongs(ctx context.Context, filter SongFilter, withText bool, fn func(song *SongModel) error) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongRepository.ExportSongs")
+	defer span.End()
+
+	where, args, err := songFilterWhere(filter)
+	if err != nil {
+		return err
+	}
+
+	declare := fmt.Sprintf(`
+		DECLARE songs_export NO SCROLL CURSOR FOR
+		SELECT
+			s.id,
+			s.song,
+			s."group",
+			s.release_date,
+			CASE WHEN $%[3]d THEN s."text" ELSE '{}' END,
+			s.link,
+			s.explicit
+		FROM %[1]s s
+		WHERE %[2]s
+		ORDER BY s.id
+	`, songsTable, where, len(args)+1)
+
+	fetch := fmt.Sprintf(`FETCH %d FROM songs_export`, exportFetchSize)
+
+	return r.db.BeginTxFunc(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, declare, append(args, withText)...); err != nil {
+			return err
+		}
+
+		for {
+			rows, err := tx.Query(ctx, fetch)
+			if err != nil {
+				return err
+			}
+
+			fetched := 0
+			for rows.Next() {
+				var song SongModel
+				err = rows.Scan(
+					&song.ID,
+					&song.Song,
+					&song.Group,
+					&song.ReleaseDate,
+					&song.Text,
+					&song.Link,
+					&song.Explicit,
+				)
+				if err == nil {
+					err = fn(&song)
+				}
+
+				if err != nil {
+					rows.Close()
+					return err
+				}
+
+				fetched++
+			}
+
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			if fetched < exportFetchSize {
+				return nil
+			}
+		}
+	})
+}
+
+func (r *SongRepository) GetQualityReport(ctx context.Context) (*QualityReport, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE cardinality("text") = 0),
+			COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM unnest("text") AS couplet WHERE btrim(couplet) = '')),
+			COUNT(*) FILTER (WHERE link = ''),
+			COUNT(*) FILTER (WHERE link <> '' AND link !~* '^https?://'),
+			COUNT(*) FILTER (WHERE release_date = '2000-01-01'),
+			(
+				SELECT COALESCE(SUM(copies - 1), 0)
+				FROM (
+					SELECT COUNT(*) AS copies
+					FROM %[1]s
+					GROUP BY LOWER("group"), LOWER(song)
+					HAVING COUNT(*) > 1
+				) duplicates
+			)
+		FROM %[1]s
+	`, songsTable)
+
+	var report QualityReport
+	err := r.db.QueryRow(ctx, query).Scan(
+		&report.TotalSongs,
+		&report.MissingLyrics,
+		&report.EmptyCouplets,
+		&report.MissingLink,
+		&report.InvalidLink,
+		&report.DefaultReleaseDate,
+		&report.DuplicateSongs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// MaintainIndexes rebuilds the B-tree indexes of the songs and of the lyrics data filters
+// rely on, dropping the bloat left by updates, and refreshes the planner statistics.
+// There are no full-text search indexes to rebuild, lyrics are matched with LIKE.
+//
+// Indexes are rebuilt concurrently, so reads and writes go on meanwhile. REINDEX CONCURRENTLY
+// cannot run in a transaction, so every statement is executed on its own.
+func (r *SongRepository) MaintainIndexes(ctx context.Context) error {
+	for _, table := range []string{songsTable, languagesTable, translationsTable} {
+		if _, err := r.db.Exec(ctx, fmt.Sprintf(`REINDEX TABLE CONCURRENTLY %s`, table)); err != nil {
+			return err
+		}
+
+		if _, err := r.db.Exec(ctx, fmt.Sprintf(`ANALYZE %s`, table)); err != nil {
+			return err
+		}
+	}
+
+	logging.FromContext(ctx).Debug("song indexes rebuilt")
+	return nil
 }
@@ -4,90 +4,705 @@ import (
 	"context"
 	"effective-mobile/go/config"
 	"effective-mobile/go/internal/common"
-	"encoding/json"
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/tracing"
+	"effective-mobile/go/pkg/profanity"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
 	log "github.com/sirupsen/logrus"
 )
 
 type SongService struct {
 	config *config.Config
 	repo   *SongRepository
+	client *http.Client
+	stats  *statsCache
+	// profanity lists words that make lyrics explicit.
+	profanity *profanity.Lists
+	// detailBreaker stops requests to the detail API while it keeps failing.
+	detailBreaker *breaker
 }
 
+var defaultReleaseDate = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 func NewSongService(cfg *config.Config, repo *SongRepository) *SongService {
+	lists, err := profanity.Load(cfg.ProfanityListsDir)
+	if err != nil {
+		log.Error("failed to load profanity lists, using the built-in ones: ", err)
+		lists = profanity.Default()
+	}
+
 	return &SongService{
-		config: cfg,
-		repo:   repo,
+		config:        cfg,
+		repo:          repo,
+		client:        &http.Client{Timeout: detailRequestTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		stats:         newStatsCache(cfg.LyricsStatsCacheTTL),
+		profanity:     lists,
+		detailBreaker: newBreaker(cfg.DetailAPIBreaker.Threshold, cfg.DetailAPIBreaker.Cooldown),
 	}
 }
 
 func (s *SongService) CreateSong(ctx context.Context, song *SongModel) error {
-	defaultDate, err := time.Parse(time.DateOnly, "2000-01-01")
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.CreateSong")
+	defer span.End()
+
+	if err := s.enrich(ctx, song); err != nil {
+		if s.config.Mode != "development" {
+			return ErrServiceUnavailable
+		}
+
+		logging.FromContext(ctx).Error("failed to get song details: ", err)
+	}
+
+	if song.ReleaseDate.IsZero() {
+		song.ReleaseDate = defaultReleaseDate
+	}
+
+	analysis := s.analyzeLyrics(song.Text)
+	song.Languages, song.Explicit = analysis.Languages, analysis.Explicit
+
+	return s.repo.CreateSong(ctx, song)
+}
+
+func (s *SongService) DeleteSong(ctx context.Context, songID int) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.DeleteSong")
+	defer span.End()
+
+	_, err := s.repo.DeleteSong(ctx, songID)
+	return err
+}
+
+// GetSong returns the song along with the related data listed in includes.
+func (s *SongService) GetSong(ctx context.Context, songID int, includes []string) (*SongDetails, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.GetSong")
+	defer span.End()
+
+	song, err := s.repo.GetSong(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &SongDetails{Song: song, Includes: includes}
+	if slices.Contains(includes, IncludeActivity) {
+		details.Activity, err = s.repo.GetSongActivity(ctx, songID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if slices.Contains(includes, IncludeLanguages) {
+		details.Languages, err = s.repo.GetSongLanguages(ctx, songID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if slices.Contains(includes, IncludeTags) {
+		details.Tags, err = s.repo.GetSongTags(ctx, songID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if slices.Contains(includes, IncludeRevisions) {
+		details.Revisions, err = s.repo.GetSongRevision(ctx, songID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return details, nil
+}
+
+// ReplaceTags replaces the tags of the song, returning them as stored.
+func (s *SongService) ReplaceTags(ctx context.Context, songID int, tags []string) ([]string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.ReplaceTags")
+	defer span.End()
+
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceSongTags(ctx, songID, tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// GetSongLyrics returns a page of couplets, see SongRepository.GetSongLyrics. With mask set,
+// words of the profanity lists are masked, in the original by the lists of the detected languages
+// and in the translation by the list of its language.
+func (s *SongService) GetSongLyrics(ctx context.Context, songID int, page, limit int, language string, mask bool) ([]*Couplet, *common.PaginationMetadata, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.GetSongLyrics")
+	defer span.End()
+
+	couplets, metadata, err := s.repo.GetSongLyrics(ctx, songID, page, limit, language)
+	if err != nil || !mask {
+		return couplets, metadata, err
+	}
+
+	languages, err := s.repo.GetSongLanguages(ctx, songID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maskCouplets(s.profanity, couplets, languages, language)
+	return couplets, metadata, nil
+}
+
+// ResolveLanguage picks the translation lyrics are returned in. An explicitly requested language
+// must have a translation, otherwise the Accept-Language header is matched against the translations.
+// An empty language stands for the original lyrics.
+func (s *SongService) ResolveLanguage(ctx context.Context, songID int, language, acceptLanguage string) (string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.ResolveLanguage")
+	defer span.End()
+
+	if language == "" && acceptLanguage == "" {
+		return "", nil
+	}
+
+	translations, err := s.repo.GetTranslations(ctx, songID)
+	if err != nil {
+		return "", err
+	}
+
+	available := make([]string, 0, len(translations))
+	for _, translation := range translations {
+		available = append(available, translation.Language)
+	}
+
+	if language == "" {
+		return matchAcceptLanguage(acceptLanguage, available), nil
+	}
+
+	language, err = NormalizeLanguage(language)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.Contains(available, language) {
+		return "", ErrTranslationNotFound
+	}
+
+	return language, nil
+}
+
+func (s *SongService) GetTranslations(ctx context.Context, songID int) ([]*TranslationSummary, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.GetTranslations")
+	defer span.End()
+
+	if _, err := s.repo.GetSong(ctx, songID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTranslations(ctx, songID)
+}
+
+func (s *SongService) ReplaceTranslation(ctx context.Context, songID int, language string, text []string) (*TranslationSummary, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.ReplaceTranslation")
+	defer span.End()
+
+	language, err := NormalizeLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReplaceTranslation(ctx, songID, language, text)
+}
+
+func (s *SongService) DeleteTranslation(ctx context.Context, songID int, language string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.DeleteTranslation")
+	defer span.End()
+
+	language, err := NormalizeLanguage(language)
 	if err != nil {
 		return err
 	}
 
-	query := fmt.Sprintf("%s/info?group=%s&song=%s", s.config.SongDetailAPI, song.Group, song.Song)
-	resp, err := http.Get(query)
+	return s.repo.DeleteTranslation(ctx, songID, language)
+}
+
+func (s *SongService) GetCouplet(ctx context.Context, songID int, ref CoupletRef) (*Couplet, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.GetCouplet")
+	defer span.End()
+
+	return s.repo.GetCouplet(ctx, songID, ref)
+}
+
+func (s *SongService) ReplaceCouplet(ctx context.Context, songID int, ref CoupletRef, text string) (*Couplet, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.ReplaceCouplet")
+	defer span.End()
+
+	return s.repo.ReplaceCouplet(ctx, songID, ref, text, s.analyzeLyrics)
+}
+
+func (s *SongService) InsertCouplet(ctx context.Context, songID int, index *int, text string) (*Couplet, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.InsertCouplet")
+	defer span.End()
+
+	return s.repo.InsertCouplet(ctx, songID, index, text, s.analyzeLyrics)
+}
+
+func (s *SongService) DeleteCouplet(ctx context.Context, songID int, ref CoupletRef) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.DeleteCouplet")
+	defer span.End()
+
+	return s.repo.DeleteCouplet(ctx, songID, ref, s.analyzeLyrics)
+}
+
+func (s *SongService) MoveCouplet(ctx context.Context, songID int, ref CoupletRef, index int) (*Couplet, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.MoveCouplet")
+	defer span.End()
+
+	return s.repo.MoveCouplet(ctx, songID, ref, index)
+}
+
+func (s *SongService) GetSongs(ctx context.Context, filter SongFilter, page SongPage, fields []string) ([]*SongModel, *common.PaginationMetadata, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.GetSongs")
+	defer span.End()
+
+	return s.repo.GetSongs(ctx, filter, page, fields)
+}
+
+func (s *SongService) ExportSongs(ctx context.Context, filter SongFilter, w ExportWriter, withText bool) (int, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.ExportSongs")
+	defer span.End()
+
+	if err := w.Begin(); err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	err := s.repo.ExportSongs(ctx, filter, withText, func(song *SongModel) error {
+		exported++
+		return w.Write(song)
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	return exported, w.End()
+}
+
+// RefreshDetails fetches the song details from the detail API again. Without
+// overwrite only the missing fields are filled, otherwise all of them are replaced.
+func (s *SongService) RefreshDetails(ctx context.Context, songID int, overwrite bool) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.RefreshDetails")
+	defer span.End()
+
+	song, err := s.repo.GetSong(ctx, songID)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var details struct {
-			ReleaseDate string `json:"releaseDate"`
-			Text        string `json:"text"`
-			Link        string `json:"link"`
+	if overwrite {
+		song.ReleaseDate = time.Time{}
+		song.Text = nil
+		song.Link = ""
+	}
+
+	if err := s.enrich(ctx, song); err != nil {
+		return err
+	}
+
+	if song.ReleaseDate.IsZero() {
+		song.ReleaseDate = defaultReleaseDate
+	}
+
+	if song.Text == nil {
+		song.Text = make([]string, 0)
+	}
+
+	return s.updateSong(ctx, UpdateSongDTO{
+		SongID:      song.ID,
+		ReleaseDate: &song.ReleaseDate,
+		Text:        &song.Text,
+		Link:        &song.Link,
+	}, EventSongEnriched)
+}
+
+// GetSongIDs returns IDs of all songs, or only of the ones lacking lyrics or a link.
+func (s *SongService) GetSongIDs(ctx context.Context, onlyIncomplete bool) ([]int, error) {
+	return s.repo.GetSongIDs(ctx, onlyIncomplete)
+}
+
+func (s *SongService) GetQualityReport(ctx context.Context) (*QualityReport, error) {
+	return s.repo.GetQualityReport(ctx)
+}
+
+func (s *SongService) MaintainIndexes(ctx context.Context) error {
+	return s.repo.MaintainIndexes(ctx)
+}
+
+func (s *SongService) UpdateSong(ctx context.Context, dto UpdateSongDTO) error {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.UpdateSong")
+	defer span.End()
+
+	return s.updateSong(ctx, dto, EventSongUpdated)
+}
+
+// updateSong stores the changes along with the analysis of new lyrics, recording them as an event of the given type.
+func (s *SongService) updateSong(ctx context.Context, dto UpdateSongDTO, eventType string) error {
+	var analysis *LyricsAnalysis
+	if dto.Text != nil {
+		analysis = s.analyzeLyrics(*dto.Text)
+	}
+
+	return s.repo.UpdateSong(ctx, dto, analysis, eventType)
+}
+
+// AnalyzeLyrics detects the languages of the song's lyrics and whether they are explicit again,
+// and stores the result.
+func (s *SongService) AnalyzeLyrics(ctx context.Context, songID int) (*LyricsAnalysis, error) {
+	song, err := s.repo.GetSong(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := s.analyzeLyrics(song.Text)
+	if err := s.repo.ReplaceLyricsAnalysis(ctx, songID, analysis); err != nil {
+		return nil, err
+	}
+
+	return analysis, nil
+}
+
+// analyzeLyrics checks the lyrics against the profanity lists of their languages.
+func (s *SongService) analyzeLyrics(text []string) *LyricsAnalysis {
+	languages := detectLanguages(text)
+
+	return &LyricsAnalysis{
+		Languages: languages,
+		Explicit:  s.profanity.Contains(strings.Join(text, "\n"), profanityLanguages(languages)),
+	}
+}
+
+// SetExplicit overrides the explicit flag of the song, or hands it back to detection when explicit is nil.
+func (s *SongService) SetExplicit(ctx context.Context, songID int, explicit *bool) (*SongModel, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.SetExplicit")
+	defer span.End()
+
+	if err := s.repo.SetExplicitOverride(ctx, songID, explicit); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSong(ctx, songID)
+}
+
+func (s *SongService) GetLibraryStats(ctx context.Context, filter SongFilter, opts LibraryStatsOptions) (*LibraryStats, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.GetLibraryStats")
+	defer span.End()
+
+	return s.repo.GetLibraryStats(ctx, filter, opts)
+}
+
+// GetLyricsStats computes statistics of the song's lyrics.
+func (s *SongService) GetLyricsStats(ctx context.Context, songID int, opts StatsOptions) (*LyricsStats, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.GetLyricsStats")
+	defer span.End()
+
+	song, err := s.repo.GetSong(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeLyricsStats([][]string{song.Text}, opts)
+}
+
+// GetGroupLyricsStats computes statistics over the lyrics of every song of the group.
+// Results are cached for the configured TTL, so they may lag behind recent edits.
+func (s *SongService) GetGroupLyricsStats(ctx context.Context, group string, opts StatsOptions) (*LyricsStats, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.GetGroupLyricsStats")
+	defer span.End()
+
+	key := fmt.Sprintf("%s\x00%s\x00%d", strings.ToLower(group), opts.StopWords, opts.Top)
+	if stats, ok := s.stats.get(key); ok {
+		return stats, nil
+	}
+
+	lyrics, err := s.repo.GetGroupLyrics(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := computeLyricsStats(lyrics, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.stats.put(key, stats)
+	return stats, nil
+}
+
+// GetUndetectedSongIDs returns IDs of songs whose languages have never been detected.
+func (s *SongService) GetUndetectedSongIDs(ctx context.Context) ([]int, error) {
+	return s.repo.GetUndetectedSongIDs(ctx)
+}
+
+// ImportSongs reads songs from the import stream and writes them in batches as they are read.
+// In atomic mode the whole import runs in a single transaction that is rolled back if any
+// row fails. In best-effort mode every batch is committed on its own, and a failed batch
+// is retried row by row to pinpoint the offending rows.
+func (s *SongService) ImportSongs(ctx context.Context, reader ImportReader, opts ImportOptions) (*ImportReport, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SongService.ImportSongs")
+	defer span.End()
+
+	report := &ImportReport{
+		Mode: opts.Mode,
+		Rows: make([]ImportRowResult, 0),
+	}
+
+	var tx pgx.Tx
+	if opts.Mode == ImportModeAtomic {
+		var err error
+		tx, err = s.repo.BeginTx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback(ctx)
+	}
+
+	batchSize := max(1, s.config.ImportBatchSize)
+	batch := make([]importRow, 0, batchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		switch {
+		case tx == nil:
+			s.insertImportBatch(ctx, report, s.prepareImportBatch(ctx, report, batch))
+		// Nothing will be committed after a failure in atomic mode, so skip the remaining work.
+		case report.Failed == 0:
+			s.copyImportBatch(ctx, tx, report, s.prepareImportBatch(ctx, report, batch))
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
 		}
-		if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
-			return err
+
+		row := ImportRowResult{Row: len(report.Rows) + 1, Status: ImportStatusFailed}
+
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			row.Errors = []string{rowErr.Error()}
+			report.Rows = append(report.Rows, row)
+			report.Failed++
+			continue
 		}
 
-		release_date, err := time.Parse("2006-01-02", details.ReleaseDate)
+		// The stream itself is broken, nothing after this point can be read.
 		if err != nil {
-			return err
+			row.Errors = []string{err.Error()}
+			report.Rows = append(report.Rows, row)
+			report.Failed++
+			break
 		}
 
-		song.ReleaseDate = release_date
-		song.Text = strings.Split(details.Text, "\n\n")
-		song.Link = details.Link
-	default:
-		if s.config.Mode != "development" {
-			return ErrServiceUnavailable
+		song, err := record.toModel()
+		if err != nil {
+			row.Errors = common.FormatErrorResponse(ctx, "", err).Errors
+			report.Rows = append(report.Rows, row)
+			report.Failed++
+			continue
 		}
 
-		log.Error("failed to get song details: ", resp.StatusCode)
+		enrich := opts.Enrich
+		if record.Enrich != nil {
+			enrich = *record.Enrich
+		}
+
+		row.Status = ImportStatusSkipped
+		report.Rows = append(report.Rows, row)
+		batch = append(batch, importRow{index: len(report.Rows) - 1, song: song, enrich: enrich})
+
+		if len(batch) >= batchSize {
+			flush()
+		}
 	}
 
-	if song.ReleaseDate.IsZero() {
-		song.ReleaseDate = defaultDate
+	flush()
+
+	report.Total = len(report.Rows)
+
+	if tx != nil {
+		if report.Failed > 0 {
+			for i := range report.Rows {
+				if report.Rows[i].Status == ImportStatusCreated {
+					report.Rows[i].Status = ImportStatusSkipped
+					report.Rows[i].ID = 0
+				}
+			}
+
+			return report, nil
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := s.repo.CreateSong(ctx, song); err != nil {
-		return err
+	for _, row := range report.Rows {
+		if row.Status == ImportStatusCreated {
+			report.Created++
+		}
 	}
 
-	return nil
+	report.Committed = true
+	logging.FromContext(ctx).Debug("imported ", report.Created, " songs, ", report.Failed, " rows failed")
+
+	return report, nil
 }
 
-func (s *SongService) DeleteSong(ctx context.Context, songID int) error {
-	return s.repo.DeleteSong(ctx, songID)
+type importRow struct {
+	index  int
+	song   *SongModel
+	enrich bool
 }
 
-func (s *SongService) GetSongLyrics(ctx context.Context, songID int, page, limit int) ([]string, *common.PaginationMetadata, error) {
-	return s.repo.GetSongLyrics(ctx, songID, page, limit)
+// importEnrichWorkers limits concurrent detail API requests of a single import.
+const importEnrichWorkers = 8
+
+// prepareImportBatch enriches and analyses the songs of the batch, returning the rows to write.
+// Rows that cannot be enriched fail outside of development mode.
+func (s *SongService) prepareImportBatch(ctx context.Context, report *ImportReport, batch []importRow) []importRow {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, importEnrichWorkers)
+	enrichErrs := make([]error, len(batch))
+
+	// Rows waiting for a worker count towards the queue until enrich takes them over.
+	waiting := 0
+	for _, row := range batch {
+		if row.enrich {
+			waiting++
+		}
+	}
+
+	enrichmentQueueDepth.Add(float64(waiting))
+
+	for i, row := range batch {
+		if !row.enrich {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		enrichmentQueueDepth.Dec()
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			enrichErrs[i] = s.enrich(ctx, row.song)
+		}()
+	}
+
+	wg.Wait()
+
+	rows := make([]importRow, 0, len(batch))
+	for i, row := range batch {
+		if err := enrichErrs[i]; err != nil {
+			if s.config.Mode != "development" {
+				failRow(report, row, fmt.Errorf("%w: %w", ErrServiceUnavailable, err))
+				continue
+			}
+
+			logging.FromContext(ctx).Error("failed to get song details: ", err)
+		} else if row.enrich {
+			report.Rows[row.index].Enriched = true
+		}
+
+		if row.song.ReleaseDate.IsZero() {
+			row.song.ReleaseDate = defaultReleaseDate
+		}
+
+		analysis := s.analyzeLyrics(row.song.Text)
+		row.song.Languages, row.song.Explicit = analysis.Languages, analysis.Explicit
+
+		rows = append(rows, row)
+	}
+
+	return rows
 }
 
-func (s *SongService) GetSongs(ctx context.Context, filter SongFilter, page, limit int) ([]*SongModel, *common.PaginationMetadata, error) {
-	return s.repo.GetSongs(ctx, filter, page, limit)
+// insertImportBatch commits the rows at once, falling back to one row at a time
+// to pinpoint the offending rows when the batch fails.
+func (s *SongService) insertImportBatch(ctx context.Context, report *ImportReport, rows []importRow) {
+	if len(rows) == 0 {
+		return
+	}
+
+	if err := s.repo.InsertSongs(ctx, importSongs(rows)); err == nil {
+		markCreated(report, rows)
+		return
+	}
+
+	for _, row := range rows {
+		if err := s.repo.InsertSongs(ctx, []*SongModel{row.song}); err != nil {
+			failRow(report, row, err)
+			continue
+		}
+
+		markCreated(report, []importRow{row})
+	}
 }
 
-func (s *SongService) UpdateSong(ctx context.Context, dto UpdateSongDTO) error {
-	return s.repo.UpdateSong(ctx, dto)
+// copyImportBatch writes the rows within the transaction of an atomic import.
+// A failed batch fails its rows, so that the transaction is not committed.
+func (s *SongService) copyImportBatch(ctx context.Context, tx pgx.Tx, report *ImportReport, rows []importRow) {
+	if len(rows) == 0 {
+		return
+	}
+
+	if err := s.repo.CopySongs(ctx, tx, importSongs(rows)); err != nil {
+		for _, row := range rows {
+			failRow(report, row, err)
+		}
+
+		return
+	}
+
+	markCreated(report, rows)
+}
+
+func importSongs(rows []importRow) []*SongModel {
+	songs := make([]*SongModel, 0, len(rows))
+	for _, row := range rows {
+		songs = append(songs, row.song)
+	}
+
+	return songs
+}
+
+func failRow(report *ImportReport, row importRow, err error) {
+	if report.Rows[row.index].Status != ImportStatusFailed {
+		report.Failed++
+	}
+
+	report.Rows[row.index].Status = ImportStatusFailed
+	report.Rows[row.index].Errors = append(report.Rows[row.index].Errors, err.Error())
+}
+
+func markCreated(report *ImportReport, rows []importRow) {
+	for _, row := range rows {
+		report.Rows[row.index].Status = ImportStatusCreated
+		report.Rows[row.index].ID = row.song.ID
+	}
 }
@@ -0,0 +1,201 @@
+package song
+
+import (
+	"effective-mobile/go/pkg/langdetect"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// StopWordsAuto excludes stop words of the languages detected in the lyrics.
+	StopWordsAuto = "auto"
+	// StopWordsNone counts every word.
+	StopWordsNone = "none"
+)
+
+// readingWordsPerMinute is the reading speed reading times are estimated for.
+const readingWordsPerMinute = 200
+
+type StatsOptions struct {
+	// StopWords is StopWordsAuto, StopWordsNone or a comma-separated list of languages.
+	StopWords string
+	// Top is the number of most frequent words to report.
+	Top int
+}
+
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+// LyricsStats describes the lyrics of one or more songs.
+type LyricsStats struct {
+	Songs       int
+	Couplets    int
+	Lines       int
+	Words       int
+	UniqueWords int
+	// UniqueWordRatio is the share of distinct words among all words.
+	UniqueWordRatio float64
+	// TopWords are the most frequent words other than stop words.
+	TopWords    []WordCount
+	ReadingTime time.Duration
+	// RepetitionScore is the share of lines repeating an earlier line of the same song.
+	RepetitionScore float64
+	// StopWords lists the languages whose stop words were left out of TopWords.
+	StopWords  []string
+	ComputedAt time.Time
+}
+
+// computeLyricsStats aggregates the lyrics of the songs.
+func computeLyricsStats(lyrics [][]string, opts StatsOptions) (*LyricsStats, error) {
+	languages, err := stopWordLanguages(opts.StopWords, lyrics)
+	if err != nil {
+		return nil, err
+	}
+
+	stopWords := make(map[string]bool)
+	for _, language := range languages {
+		for word := range langdetect.StopWords(language) {
+			stopWords[word] = true
+		}
+	}
+
+	stats := &LyricsStats{Songs: len(lyrics), StopWords: languages, ComputedAt: time.Now()}
+
+	counts := make(map[string]int)
+	repeated := 0
+	for _, text := range lyrics {
+		stats.Couplets += len(text)
+
+		seen := make(map[string]bool)
+		for _, couplet := range text {
+			for _, line := range strings.Split(couplet, "\n") {
+				words := langdetect.Words(line)
+				if len(words) == 0 {
+					continue
+				}
+
+				stats.Lines++
+				stats.Words += len(words)
+				for _, word := range words {
+					counts[word]++
+				}
+
+				normalized := strings.Join(words, " ")
+				if seen[normalized] {
+					repeated++
+				}
+				seen[normalized] = true
+			}
+		}
+	}
+
+	stats.UniqueWords = len(counts)
+	if stats.Words > 0 {
+		stats.UniqueWordRatio = float64(stats.UniqueWords) / float64(stats.Words)
+	}
+
+	if stats.Lines > 0 {
+		stats.RepetitionScore = float64(repeated) / float64(stats.Lines)
+	}
+
+	stats.ReadingTime = time.Duration(stats.Words) * time.Minute / readingWordsPerMinute
+
+	stats.TopWords = make([]WordCount, 0, len(counts))
+	for word, count := range counts {
+		if !stopWords[word] {
+			stats.TopWords = append(stats.TopWords, WordCount{Word: word, Count: count})
+		}
+	}
+
+	sort.Slice(stats.TopWords, func(i, j int) bool {
+		if stats.TopWords[i].Count != stats.TopWords[j].Count {
+			return stats.TopWords[i].Count > stats.TopWords[j].Count
+		}
+
+		return stats.TopWords[i].Word < stats.TopWords[j].Word
+	})
+
+	stats.TopWords = stats.TopWords[:min(len(stats.TopWords), max(0, opts.Top))]
+
+	return stats, nil
+}
+
+// stopWordLanguages resolves the stop words option to a list of languages.
+func stopWordLanguages(option string, lyrics [][]string) ([]string, error) {
+	switch option {
+	case StopWordsNone:
+		return []string{}, nil
+	case "", StopWordsAuto:
+		seen := make(map[string]bool)
+		languages := make([]string, 0)
+		for _, text := range lyrics {
+			for _, result := range langdetect.Detect(strings.Join(text, "\n")) {
+				if !seen[result.Language] {
+					seen[result.Language] = true
+					languages = append(languages, result.Language)
+				}
+			}
+		}
+
+		sort.Strings(languages)
+		return languages, nil
+	}
+
+	languages := make([]string, 0)
+	for _, language := range strings.Split(option, ",") {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if langdetect.StopWords(language) == nil {
+			return nil, fmt.Errorf("%w: no stop words for %q", ErrInvalidStopWords, language)
+		}
+
+		languages = append(languages, language)
+	}
+
+	return languages, nil
+}
+
+// statsCache keeps aggregate statistics for a while, as they read every song of a group.
+type statsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*LyricsStats
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	return &statsCache{ttl: ttl, entries: make(map[string]*LyricsStats)}
+}
+
+func (c *statsCache) get(key string) (*LyricsStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats, ok := c.entries[key]
+	if !ok || time.Since(stats.ComputedAt) >= c.ttl {
+		return nil, false
+	}
+
+	return stats, true
+}
+
+// put stores the statistics, dropping expired entries on the way. A zero TTL disables caching.
+func (c *statsCache) put(key string, stats *LyricsStats) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if time.Since(entry.ComputedAt) >= c.ttl {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = stats
+}
@@ -0,0 +1,33 @@
+package song
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxSongTags   = 20
+	maxSongTagLen = 64
+)
+
+// NormalizeTags trims and lowercases the tags, dropping repeated ones, and sorts them.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxSongTags {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidTags, maxSongTags)
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > maxSongTagLen {
+			return nil, fmt.Errorf("%w: tags must be 1 to %d characters long", ErrInvalidTags, maxSongTagLen)
+		}
+
+		normalized = append(normalized, tag)
+	}
+
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
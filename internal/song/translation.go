@@ -0,0 +1,70 @@
+package song
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// NormalizeLanguage validates a language code such as "en" or "pt-br" and lowercases it.
+func NormalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if len(language) > 16 || !languagePattern.MatchString(language) {
+		return "", ErrInvalidLanguage
+	}
+
+	return language, nil
+}
+
+// matchAcceptLanguage picks the available language the Accept-Language header prefers most.
+// A range such as "en" also matches regional variants like "en-gb", and the other way round.
+// An empty string means that none of the languages is acceptable.
+func matchAcceptLanguage(header string, available []string) string {
+	type languageRange struct {
+		tag     string
+		quality float64
+	}
+
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && tag != "*" && quality > 0 {
+			ranges = append(ranges, languageRange{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, r := range ranges {
+		for _, language := range available {
+			if language == r.tag {
+				return language
+			}
+		}
+
+		for _, language := range available {
+			if strings.HasPrefix(language, r.tag+"-") || strings.HasPrefix(r.tag, language+"-") {
+				return language
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,28 @@
+package stream
+
+import (
+	"effective-mobile/go/internal/outbox"
+	"encoding/json"
+	"time"
+)
+
+// swagger:model EventDTO
+type EventDTO struct {
+	// ID of the event, sent as the id of the message as well
+	// example: 3f1c9a0e8b7d4c2a9e6f5d4c3b2a1908
+	ID string `json:"id"`
+	// example: song.updated
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Payload of the event, song_id along with the song unless it has been deleted for song events
+	Data json.RawMessage `json:"data"`
+}
+
+func toEventDTO(event *outbox.Event) EventDTO {
+	return EventDTO{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Payload,
+	}
+}
@@ -0,0 +1,9 @@
+package stream
+
+import "errors"
+
+var (
+	ErrUnknownEventType = errors.New("unknown event type")
+	ErrNotListening     = errors.New("not listening for events")
+	ErrStreamsClosed    = errors.New("event streams are closed")
+)
@@ -0,0 +1,179 @@
+package stream
+
+import (
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StreamHandler struct {
+	cfg     *config.Config
+	service *StreamService
+}
+
+func NewStreamHandler(cfg *config.Config, service *StreamService) *StreamHandler {
+	return &StreamHandler{
+		cfg:     cfg,
+		service: service,
+	}
+}
+
+// retryDelay is the reconnection delay suggested to clients.
+const retryDelay = 3 * time.Second
+
+// swagger:route GET /events Events GetEvents
+// Stream song events as Server-Sent Events
+//
+// Every message carries the event ID as its id and the event type as its event, with an EventDTO
+// as data. Comments are sent while the stream is idle to keep it open. Clients reconnecting with
+// the Last-Event-ID header are first sent the recent events they missed; when the event is no
+// longer recent, every recent event is sent and duplicates are to be dropped by ID.
+//
+// Produces:
+// - text/event-stream
+//
+// responses:
+//
+//	200: EventStreamResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	503: ErrorResponse
+func (h *StreamHandler) GetEvents(ctx *gin.Context) {
+	// swagger:parameters GetEvents
+	type requestDescription struct {
+		// Comma-separated event types to stream, all of them by default
+		// in: query
+		// required: false
+		// example: song.created,song.deleted
+		Type string `form:"type" json:"type"`
+		// Comma-separated IDs of the songs to stream events of, all of them by default
+		// in: query
+		// required: false
+		// example: 1,2,3
+		SongID string `form:"song_id" json:"song_id"`
+		// ID of the last event received, to resume the stream after it
+		// in: header
+		// required: false
+		LastEventID string `json:"Last-Event-ID"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	req.LastEventID = ctx.GetHeader("Last-Event-ID")
+
+	filter := Filter{Types: splitList(req.Type), SongIDs: splitList(req.SongID)}
+	for i, id := range filter.SongIDs {
+		songID, err := strconv.Atoi(id)
+		if err != nil || songID <= 0 {
+			ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", fmt.Errorf("invalid song ID %q", id)))
+			return
+		}
+
+		// Keys are compared as strings, so IDs are normalised first, e.g. "007" to "7".
+		filter.SongIDs[i] = strconv.Itoa(songID)
+	}
+
+	replay, subscription, err := h.service.Subscribe(ctx, filter, req.LastEventID)
+	if errors.Is(err, ErrUnknownEventType) {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+	if errors.Is(err, ErrStreamsClosed) {
+		ctx.JSON(http.StatusServiceUnavailable, common.FormatErrorResponse(ctx, "failed to subscribe to events", err))
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to subscribe to events: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, "failed to subscribe to events", err))
+		return
+	}
+	defer subscription.Close()
+
+	// swagger:response EventStreamResponse
+	type responseDescription struct {
+		// Stream of events in the text/event-stream format
+		// in: body
+		Body string
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// Proxies such as nginx would otherwise hold the messages back.
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	w := ctx.Writer
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryDelay.Milliseconds()); err != nil {
+		return
+	}
+
+	for _, event := range replay {
+		if err := writeEvent(w, toEventDTO(event)); err != nil {
+			return
+		}
+	}
+
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.cfg.Events.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			// The stream fell behind or the server is shutting down, the client resumes on reconnect.
+			if !ok {
+				return
+			}
+
+			if err := writeEvent(w, toEventDTO(event)); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		w.Flush()
+	}
+}
+
+// writeEvent writes the event as a message of the stream.
+func writeEvent(w gin.ResponseWriter, event EventDTO) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
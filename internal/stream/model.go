@@ -0,0 +1,21 @@
+package stream
+
+import (
+	"effective-mobile/go/internal/outbox"
+	"slices"
+)
+
+// Filter selects the events of a stream. Empty lists select every event.
+type Filter struct {
+	Types []string
+	// SongIDs are matched against the keys of the events, which are song IDs for song events.
+	SongIDs []string
+}
+
+func (f Filter) Matches(event *outbox.Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+
+	return len(f.SongIDs) == 0 || slices.Contains(f.SongIDs, event.Key)
+}
@@ -0,0 +1,130 @@
+package stream
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/outbox"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type StreamRepository struct {
+	config *config.Config
+	db     *pgxpool.Pool
+}
+
+const outboxTable = "outbox_events"
+
+// notifyChannel is the channel outbox events are announced on, see the outbox_events_notify trigger.
+const notifyChannel = "outbox_events"
+
+const eventColumns = `seq, event_id, event_type, event_key, payload, occurred_at`
+
+func NewStreamRepository(cfg *config.Config, db *pgxpool.Pool) *StreamRepository {
+	return &StreamRepository{
+		config: cfg,
+		db:     db,
+	}
+}
+
+// Listen listens for outbox events on a connection of its own, calling ready once listening
+// and notify with the seq of every event committed from then on. It returns when the context
+// is cancelled or the connection fails.
+func (r *StreamRepository) Listen(ctx context.Context, ready func(ctx context.Context) error, notify func(ctx context.Context, seq int64)) error {
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The connection is taken out of the pool, so that it is not reused while still listening.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	if err := ready(ctx); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		seq, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			logging.FromContext(ctx).Error("invalid outbox notification ", notification.Payload, ": ", err)
+			continue
+		}
+
+		notify(ctx, seq)
+	}
+}
+
+// GetEvent returns the outbox event with the given seq, nil once it has been deleted.
+func (r *StreamRepository) GetEvent(ctx context.Context, seq int64) (*outbox.Event, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE seq = $1`, eventColumns, outboxTable)
+
+	event, err := scanEvent(r.db.QueryRow(ctx, query, seq))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	return event, err
+}
+
+// GetEventsAfter returns up to limit outbox events following the given seq in seq order.
+func (r *StreamRepository) GetEventsAfter(ctx context.Context, seq int64, limit int) ([]*outbox.Event, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2
+	`, eventColumns, outboxTable)
+
+	rows, err := r.db.Query(ctx, query, seq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*outbox.Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetLastSeq returns the seq of the latest outbox event, zero while there are none.
+func (r *StreamRepository) GetLastSeq(ctx context.Context) (int64, error) {
+	query := fmt.Sprintf(`SELECT COALESCE(MAX(seq), 0) FROM %s`, outboxTable)
+
+	var seq int64
+	err := r.db.QueryRow(ctx, query).Scan(&seq)
+	return seq, err
+}
+
+func scanEvent(row pgx.Row) (*outbox.Event, error) {
+	var event outbox.Event
+	err := row.Scan(&event.Seq, &event.ID, &event.Type, &event.Key, &event.Payload, &event.OccurredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,274 @@
+package stream
+
+import (
+	"context"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/outbox"
+	"effective-mobile/go/internal/song"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// subscriberBuffer is the number of events a subscriber may fall behind before it is dropped.
+	subscriberBuffer = 64
+	// listenRetryDelay is the wait before listening again after the connection has failed.
+	listenRetryDelay = 5 * time.Second
+)
+
+// StreamService passes the outbox events committed by any instance to the streams of this one.
+// Recent events are kept in a replay buffer, so that streams can be resumed after a reconnect.
+type StreamService struct {
+	config *config.Config
+	repo   *StreamRepository
+
+	mu sync.Mutex
+	// buffer holds the latest Events.ReplayBuffer events, oldest first.
+	buffer []*outbox.Event
+	// lastSeq is the highest seq received, events after it are caught up on after a reconnect.
+	lastSeq     int64
+	subscribers map[*Subscription]struct{}
+	// closed is set once the streams have been closed for good.
+	closed bool
+	// listening tells whether events are being listened for, listenErr why they are not.
+	listening bool
+	listenErr error
+}
+
+// Subscription is a stream of events matching a filter.
+type Subscription struct {
+	service *StreamService
+	filter  Filter
+	events  chan *outbox.Event
+	// logger is the logger of the request streaming the events.
+	logger *log.Entry
+}
+
+func NewStreamService(cfg *config.Config, repo *StreamRepository) *StreamService {
+	return &StreamService{
+		config:      cfg,
+		repo:        repo,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe returns the buffered events following the one with lastEventID that match the filter,
+// along with a subscription to the events received from now on. Unless lastEventID is empty,
+// every buffered event is returned when it is no longer buffered.
+func (s *StreamService) Subscribe(ctx context.Context, filter Filter, lastEventID string) ([]*outbox.Event, *Subscription, error) {
+	for _, eventType := range filter.Types {
+		if !slices.Contains(song.SongEventTypes, eventType) {
+			return nil, nil, fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+		}
+	}
+
+	subscription := &Subscription{
+		service: s,
+		filter:  filter,
+		events:  make(chan *outbox.Event, subscriberBuffer),
+		logger:  logging.FromContext(ctx),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, nil, ErrStreamsClosed
+	}
+
+	var replay []*outbox.Event
+	if lastEventID != "" {
+		from := slices.IndexFunc(s.buffer, func(event *outbox.Event) bool {
+			return event.ID == lastEventID
+		}) + 1
+
+		for _, event := range s.buffer[from:] {
+			if filter.Matches(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	s.subscribers[subscription] = struct{}{}
+	return replay, subscription, nil
+}
+
+// Events returns the channel of the events, closed when the subscription ends.
+func (sub *Subscription) Events() <-chan *outbox.Event {
+	return sub.events
+}
+
+// Close ends the subscription.
+func (sub *Subscription) Close() {
+	sub.service.mu.Lock()
+	defer sub.service.mu.Unlock()
+
+	sub.service.unsubscribe(sub)
+}
+
+// unsubscribe ends the subscription, the lock must be held.
+func (s *StreamService) unsubscribe(sub *Subscription) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+
+	delete(s.subscribers, sub)
+	close(sub.events)
+}
+
+// CloseStreams ends every subscription and rejects further ones.
+func (s *StreamService) CloseStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subscribers {
+		s.unsubscribe(sub)
+	}
+}
+
+// Run listens for events until the context is cancelled, listening again whenever the
+// connection fails. The subscriptions end once it returns.
+func (s *StreamService) Run(ctx context.Context) {
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).WithField("worker", "event_stream"))
+
+	defer s.CloseStreams()
+
+	for {
+		err := s.repo.Listen(ctx, s.ready, s.receive)
+
+		s.mu.Lock()
+		s.listening, s.listenErr = false, err
+		s.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		logging.FromContext(ctx).Error("failed to listen for events, retrying in ", listenRetryDelay, ": ", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+// ready catches up once listening and marks the service as listening.
+func (s *StreamService) ready(ctx context.Context) error {
+	if err := s.catchUp(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.listening, s.listenErr = true, nil
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Check fails while events are not being listened for, in which case streams receive none.
+func (s *StreamService) Check(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listening {
+		return nil
+	}
+
+	if s.listenErr != nil {
+		return fmt.Errorf("%w: %w", ErrNotListening, s.listenErr)
+	}
+
+	return ErrNotListening
+}
+
+// catchUp receives the events committed while nobody was listening. The first time
+// around only the position is taken, as streams start with the events following it.
+func (s *StreamService) catchUp(ctx context.Context) error {
+	s.mu.Lock()
+	lastSeq := s.lastSeq
+	s.mu.Unlock()
+
+	if lastSeq == 0 {
+		seq, err := s.repo.GetLastSeq(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.lastSeq = max(s.lastSeq, seq)
+		s.mu.Unlock()
+		return nil
+	}
+
+	// More events than the buffer holds may have been committed meanwhile, so they are paged through.
+	limit := max(1, s.config.Events.ReplayBuffer)
+	for {
+		events, err := s.repo.GetEventsAfter(ctx, lastSeq, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			s.broadcast(event)
+		}
+
+		if len(events) < limit {
+			return nil
+		}
+
+		lastSeq = events[len(events)-1].Seq
+	}
+}
+
+// receive loads the notified event and passes it on.
+func (s *StreamService) receive(ctx context.Context, seq int64) {
+	event, err := s.repo.GetEvent(ctx, seq)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to load event ", seq, ": ", err)
+		return
+	}
+
+	if event != nil {
+		s.broadcast(event)
+	}
+}
+
+// broadcast buffers the event and passes it to the matching subscribers. Subscribers that
+// have fallen behind are dropped rather than holding the others up; they resume from the buffer.
+func (s *StreamService) broadcast(event *outbox.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Events caught up on after a reconnect may have been received already.
+	if slices.ContainsFunc(s.buffer, func(buffered *outbox.Event) bool { return buffered.ID == event.ID }) {
+		return
+	}
+
+	s.lastSeq = max(s.lastSeq, event.Seq)
+
+	s.buffer = append(s.buffer, event)
+	if overflow := len(s.buffer) - max(1, s.config.Events.ReplayBuffer); overflow > 0 {
+		s.buffer = slices.Delete(s.buffer, 0, overflow)
+	}
+
+	for sub := range s.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			sub.logger.Warn("event stream fell behind, closing it")
+			s.unsubscribe(sub)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ConfigurePool makes the connections of the pool record their queries as spans.
+func ConfigurePool(cfg *pgxpool.Config) {
+	cfg.ConnConfig.Logger = queryTracer{}
+	cfg.ConnConfig.LogLevel = pgx.LogLevelInfo
+}
+
+// queryTracer records the queries run within a span as child spans. pgx v4 has no tracing
+// hooks, so the spans are built from its query log, which reports a query once it has completed.
+type queryTracer struct{}
+
+func (queryTracer) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	switch msg {
+	case "Query", "Exec", "CopyFrom", "SendBatch":
+	default:
+		return
+	}
+
+	// Queries outside of a traced request, e.g. those of background workers, start no traces.
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+
+	end := time.Now()
+	elapsed, _ := data["time"].(time.Duration)
+
+	attrs := []attribute.KeyValue{semconv.DBSystemPostgreSQL}
+	name := msg
+	if sql, ok := data["sql"].(string); ok {
+		attrs = append(attrs, semconv.DBQueryTextKey.String(sql))
+		if fields := strings.Fields(sql); len(fields) > 0 {
+			name = strings.ToUpper(fields[0])
+			attrs = append(attrs, semconv.DBOperationName(name))
+		}
+	}
+
+	if table, ok := data["tableName"].(pgx.Identifier); ok {
+		name = "COPY " + table.Sanitize()
+		attrs = append(attrs, semconv.DBCollectionName(table.Sanitize()))
+	}
+
+	switch rows := data["rowCount"].(type) {
+	case int:
+		attrs = append(attrs, attribute.Int("db.row_count", rows))
+	case int64:
+		attrs = append(attrs, attribute.Int64("db.row_count", rows))
+	}
+
+	if tag, ok := data["commandTag"].(pgconn.CommandTag); ok {
+		attrs = append(attrs, attribute.Int64("db.row_count", tag.RowsAffected()))
+	}
+
+	_, span := Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-elapsed)),
+		trace.WithAttributes(attrs...),
+	)
+
+	if err, ok := data["err"].(error); ok {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
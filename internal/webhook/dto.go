@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type UpdateSubscriptionDTO struct {
+	SubscriptionID int
+	URL            *string
+	EventTypes     *[]string
+	Secret         *string
+	Active         *bool
+}
+
+// swagger:model SubscriptionDTO
+type SubscriptionDTO struct {
+	ID int `json:"id"`
+	// example: https://example.com/hooks/songs
+	URL string `json:"url"`
+	// example: ["song.created","song.deleted"]
+	Events []string `json:"events"`
+	// Secret the payloads are signed with, returned only when the subscription is created
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// swagger:model DeliveryDTO
+type DeliveryDTO struct {
+	ID             int64  `json:"id"`
+	SubscriptionID int    `json:"subscription_id"`
+	EventID        string `json:"event_id"`
+	// example: song.created
+	Event string `json:"event"`
+	// enum: pending,succeeded,failed
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	// Time of the next attempt of a pending delivery
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	// HTTP status of the last response
+	ResponseStatus *int   `json:"response_status,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+	// ID of the delivery this one replays
+	ReplayOf  *int64    `json:"replay_of,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Body of the request, present when a single delivery is requested
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func (s *SubscriptionModel) ToDTO() SubscriptionDTO {
+	return SubscriptionDTO{
+		ID:        s.ID,
+		URL:       s.URL,
+		Events:    s.EventTypes,
+		Active:    s.Active,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// ToDTO converts the delivery, leaving the payload out unless withPayload is set.
+func (d *DeliveryModel) ToDTO(withPayload bool) DeliveryDTO {
+	dto := DeliveryDTO{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		Event:          d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastAttemptAt:  d.LastAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		ReplayOf:       d.ReplayOf,
+		CreatedAt:      d.CreatedAt,
+	}
+
+	if d.Status == DeliveryPending {
+		dto.NextAttemptAt = &d.NextAttemptAt
+	}
+
+	if withPayload {
+		dto.Payload = json.RawMessage(d.Payload)
+	}
+
+	return dto
+}
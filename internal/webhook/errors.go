@@ -0,0 +1,10 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrUnknownEventType     = errors.New("unknown event type")
+	ErrClaimLost            = errors.New("webhook delivery has been claimed by another worker")
+)
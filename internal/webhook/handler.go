@@ -0,0 +1,430 @@
+package webhook
+
+import (
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	cfg     *config.Config
+	service *WebhookService
+}
+
+func NewWebhookHandler(cfg *config.Config, service *WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		cfg:     cfg,
+		service: service,
+	}
+}
+
+// subscriptionIDDescription identifies a subscription by the path.
+//
+// swagger:parameters GetSubscription DeleteSubscription
+type subscriptionIDDescription struct {
+	// ID of the subscription
+	// in: path
+	// required: true
+	ID int `uri:"id" binding:"required" json:"id"`
+}
+
+// swagger:route POST /webhooks Webhooks CreateSubscription
+// Subscribe a URL to song events
+//
+// Events are posted to the URL as JSON, signed with the secret: the X-Webhook-Signature header
+// holds "sha256=" and the hex HMAC-SHA256 of the X-Webhook-Timestamp value, a dot and the body.
+// Failed deliveries are retried with exponential backoff. The secret is returned only here.
+//
+// responses:
+//
+//	201: SubscriptionResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	500: ErrorResponse
+func (h *WebhookHandler) CreateSubscription(ctx *gin.Context) {
+	// swagger:parameters CreateSubscription
+	type requestDescription struct {
+		// in: body
+		Body struct {
+			// URL the events are posted to
+			// required: true
+			// example: https://example.com/hooks/songs
+			URL string `json:"url" binding:"required,url"`
+			// Types of the events to deliver
+			// required: true
+			// example: ["song.created","song.updated","song.deleted","song.enriched"]
+			Events []string `json:"events" binding:"required,min=1"`
+			// Secret to sign the payloads with, generated when not given
+			// required: false
+			Secret string `json:"secret" binding:"omitempty,min=16"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	subscription := &SubscriptionModel{
+		URL:        req.Body.URL,
+		EventTypes: req.Body.Events,
+		Secret:     req.Body.Secret,
+		Active:     true,
+	}
+
+	if err := h.service.CreateSubscription(ctx, subscription); err != nil {
+		h.handleError(ctx, "failed to create webhook subscription", err)
+		return
+	}
+
+	// swagger:response SubscriptionResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string          `json:"message"`
+			Body    SubscriptionDTO `json:"body"`
+		}
+	}
+
+	dto := subscription.ToDTO()
+	dto.Secret = subscription.Secret
+
+	ctx.JSON(http.StatusCreated, common.BodyResponse{
+		Message: "webhook subscription successfully created",
+		Body:    dto,
+	})
+}
+
+// swagger:route GET /webhooks Webhooks GetSubscriptions
+// Get list of webhook subscriptions
+//
+// responses:
+//
+//	200: SubscriptionsResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	500: ErrorResponse
+func (h *WebhookHandler) GetSubscriptions(ctx *gin.Context) {
+	subscriptions, err := h.service.GetSubscriptions(ctx)
+	if err != nil {
+		h.handleError(ctx, "failed to get webhook subscriptions", err)
+		return
+	}
+
+	subscriptionsDTO := make([]SubscriptionDTO, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscriptionsDTO = append(subscriptionsDTO, subscription.ToDTO())
+	}
+
+	// swagger:response SubscriptionsResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string            `json:"message"`
+			Body    []SubscriptionDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "webhook subscriptions successfully retrieved",
+		Body:    subscriptionsDTO,
+	})
+}
+
+// swagger:route GET /webhooks/:id Webhooks GetSubscription
+// Get a webhook subscription
+//
+// responses:
+//
+//	200: SubscriptionResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *WebhookHandler) GetSubscription(ctx *gin.Context) {
+	var req subscriptionIDDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid subscription id", err))
+		return
+	}
+
+	subscription, err := h.service.GetSubscription(ctx, req.ID)
+	if err != nil {
+		h.handleError(ctx, "failed to get webhook subscription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "webhook subscription successfully retrieved",
+		Body:    subscription.ToDTO(),
+	})
+}
+
+// swagger:route PATCH /webhooks/:id Webhooks UpdateSubscription
+// Update a webhook subscription
+//
+// Inactive subscriptions receive no new deliveries and their pending deliveries wait
+// until they are activated again.
+//
+// responses:
+//
+//	200: SubscriptionResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *WebhookHandler) UpdateSubscription(ctx *gin.Context) {
+	// swagger:parameters UpdateSubscription
+	type requestDescription struct {
+		// ID of the subscription
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// in: body
+		Body struct {
+			// URL the events are posted to
+			// required: false
+			URL *string `json:"url" binding:"omitempty,url"`
+			// Types of the events to deliver
+			// required: false
+			Events *[]string `json:"events" binding:"omitempty,min=1"`
+			// Secret to sign the payloads with
+			// required: false
+			Secret *string `json:"secret" binding:"omitempty,min=16"`
+			// Whether events are delivered
+			// required: false
+			Active *bool `json:"active"`
+		}
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid subscription id", err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req.Body); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid request", err))
+		return
+	}
+
+	subscription, err := h.service.UpdateSubscription(ctx, UpdateSubscriptionDTO{
+		SubscriptionID: req.ID,
+		URL:            req.Body.URL,
+		EventTypes:     req.Body.Events,
+		Secret:         req.Body.Secret,
+		Active:         req.Body.Active,
+	})
+	if err != nil {
+		h.handleError(ctx, "failed to update webhook subscription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "webhook subscription successfully updated",
+		Body:    subscription.ToDTO(),
+	})
+}
+
+// swagger:route DELETE /webhooks/:id Webhooks DeleteSubscription
+// Delete a webhook subscription along with its delivery log
+//
+// responses:
+//
+//	200: Response
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *WebhookHandler) DeleteSubscription(ctx *gin.Context) {
+	var req subscriptionIDDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid subscription id", err))
+		return
+	}
+
+	if err := h.service.DeleteSubscription(ctx, req.ID); err != nil {
+		h.handleError(ctx, "failed to delete webhook subscription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, common.Response{Message: "webhook subscription successfully deleted"})
+}
+
+// swagger:route GET /webhooks/:id/deliveries Webhooks GetDeliveries
+// Get the delivery log of a webhook subscription, newest first
+//
+// responses:
+//
+//	200: DeliveriesResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *WebhookHandler) GetDeliveries(ctx *gin.Context) {
+	// swagger:parameters GetDeliveries
+	type requestDescription struct {
+		// ID of the subscription
+		// in: path
+		// required: true
+		ID int `uri:"id" binding:"required" json:"id"`
+		// Page number
+		// in: query
+		// required: false
+		// default: 1
+		Page int `form:"page,default=1" json:"page" binding:"min=1"`
+		// Number of deliveries per page
+		// in: query
+		// required: false
+		// default: 10
+		Limit int `form:"limit,default=10" json:"limit" binding:"min=1,max=50"`
+		// Status of the deliveries
+		// in: query
+		// required: false
+		// enum: pending,succeeded,failed
+		Status *string `form:"status" json:"status" binding:"omitempty,oneof=pending succeeded failed"`
+	}
+
+	var req requestDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid subscription id", err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid query", err))
+		return
+	}
+
+	deliveries, metadata, err := h.service.GetDeliveries(ctx, req.ID, DeliveryFilter{Status: req.Status}, req.Page, req.Limit)
+	if err != nil {
+		h.handleError(ctx, "failed to get webhook deliveries", err)
+		return
+	}
+
+	deliveriesDTO := make([]DeliveryDTO, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveriesDTO = append(deliveriesDTO, delivery.ToDTO(false))
+	}
+
+	// swagger:response DeliveriesResponse
+	type responseDescription struct {
+		// in: body
+		Body common.PaginationResponse[DeliveryDTO]
+	}
+
+	ctx.JSON(http.StatusOK, responseDescription{
+		Body: common.PaginationResponse[DeliveryDTO]{
+			Message:            "webhook deliveries successfully retrieved",
+			PaginationMetadata: *metadata,
+			Body:               deliveriesDTO,
+		},
+	}.Body)
+}
+
+// deliveryIDDescription identifies a delivery of a subscription by the path.
+//
+// swagger:parameters GetDelivery ReplayDelivery
+type deliveryIDDescription struct {
+	// ID of the subscription
+	// in: path
+	// required: true
+	ID int `uri:"id" binding:"required" json:"id"`
+	// ID of the delivery
+	// in: path
+	// required: true
+	DeliveryID int64 `uri:"delivery_id" binding:"required" json:"delivery_id"`
+}
+
+// swagger:route GET /webhooks/:id/deliveries/:delivery_id Webhooks GetDelivery
+// Get a webhook delivery along with its payload
+//
+// responses:
+//
+//	200: DeliveryResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *WebhookHandler) GetDelivery(ctx *gin.Context) {
+	var req deliveryIDDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid delivery id", err))
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(ctx, req.ID, req.DeliveryID)
+	if err != nil {
+		h.handleError(ctx, "failed to get webhook delivery", err)
+		return
+	}
+
+	// swagger:response DeliveryResponse
+	type responseDescription struct {
+		// in: body
+		Body struct {
+			Message string      `json:"message"`
+			Body    DeliveryDTO `json:"body"`
+		}
+	}
+
+	ctx.JSON(http.StatusOK, common.BodyResponse{
+		Message: "webhook delivery successfully retrieved",
+		Body:    delivery.ToDTO(true),
+	})
+}
+
+// swagger:route POST /webhooks/:id/deliveries/:delivery_id/replay Webhooks ReplayDelivery
+// Deliver the payload of a delivery once more
+//
+// The payload is queued as a new delivery referring to the replayed one.
+//
+// responses:
+//
+//	202: DeliveryResponse
+//	400: ErrorResponse
+//	401: ErrorResponse
+//	403: ErrorResponse
+//	404: ErrorResponse
+//	500: ErrorResponse
+func (h *WebhookHandler) ReplayDelivery(ctx *gin.Context) {
+	var req deliveryIDDescription
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, "invalid delivery id", err))
+		return
+	}
+
+	delivery, err := h.service.ReplayDelivery(ctx, req.ID, req.DeliveryID)
+	if err != nil {
+		h.handleError(ctx, "failed to replay webhook delivery", err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, common.BodyResponse{
+		Message: "webhook delivery successfully queued",
+		Body:    delivery.ToDTO(false),
+	})
+}
+
+func (h *WebhookHandler) handleError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownEventType):
+		ctx.JSON(http.StatusBadRequest, common.FormatErrorResponse(ctx, message, err))
+	case errors.Is(err, ErrSubscriptionNotFound), errors.Is(err, ErrDeliveryNotFound):
+		ctx.JSON(http.StatusNotFound, common.FormatErrorResponse(ctx, message, err))
+	default:
+		logging.FromContext(ctx).Error(message, ": ", err)
+		ctx.JSON(http.StatusInternalServerError, common.FormatErrorResponse(ctx, message, err))
+	}
+}
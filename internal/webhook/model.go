@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type SubscriptionModel struct {
+	ID         int       `db:"id"`
+	URL        string    `db:"url"`
+	EventTypes []string  `db:"event_types"`
+	Secret     string    `db:"secret"`
+	Active     bool      `db:"active"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// DeliveryModel is an event delivered, or to be delivered, to a subscription.
+type DeliveryModel struct {
+	ID             int64      `db:"id"`
+	SubscriptionID int        `db:"subscription_id"`
+	EventID        string     `db:"event_id"`
+	EventType      string     `db:"event_type"`
+	Payload        []byte     `db:"payload"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	LastAttemptAt  *time.Time `db:"last_attempt_at"`
+	ResponseStatus *int       `db:"response_status"`
+	LastError      string     `db:"last_error"`
+	ReplayOf       *int64     `db:"replay_of"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+// claimedDelivery is a delivery taken by a worker for an attempt, along with where it goes.
+type claimedDelivery struct {
+	DeliveryModel
+	// ClaimToken identifies the claim, see WebhookRepository.ClaimDueDelivery.
+	ClaimToken string
+	URL        string
+	Secret     string
+}
+
+type DeliveryFilter struct {
+	Status *string
+}
+
+// Payload is the body of webhook requests.
+type Payload struct {
+	// ID of the event, the same for every delivery of the event
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Data is the payload of the event, song.SongEventPayload for song events.
+	Data json.RawMessage `json:"data"`
+}
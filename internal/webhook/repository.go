@@ -0,0 +1,370 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/logging"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type WebhookRepository struct {
+	config *config.Config
+	db     *pgxpool.Pool
+}
+
+const (
+	subscriptionsTable = "webhook_subscriptions"
+	deliveriesTable    = "webhook_deliveries"
+)
+
+const deliveryColumns = `
+	d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_attempt_at, d.response_status, d.last_error, d.replay_of, d.created_at
+`
+
+func NewWebhookRepository(cfg *config.Config, db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{
+		config: cfg,
+		db:     db,
+	}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *SubscriptionModel) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (url, event_types, secret, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, subscriptionsTable)
+
+	err := r.db.QueryRow(ctx, query,
+		subscription.URL,
+		subscription.EventTypes,
+		subscription.Secret,
+		subscription.Active,
+	).Scan(&subscription.ID, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("webhook subscription created with ID: ", subscription.ID)
+	return nil
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, subscriptionID int) (*SubscriptionModel, error) {
+	query := fmt.Sprintf(`
+		SELECT id, url, event_types, secret, active, created_at, updated_at
+		FROM %s
+		WHERE id = $1
+	`, subscriptionsTable)
+
+	var subscription SubscriptionModel
+	err := r.db.QueryRow(ctx, query, subscriptionID).Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.EventTypes,
+		&subscription.Secret,
+		&subscription.Active,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *WebhookRepository) GetSubscriptions(ctx context.Context) ([]*SubscriptionModel, error) {
+	query := fmt.Sprintf(`
+		SELECT id, url, event_types, secret, active, created_at, updated_at
+		FROM %s
+		ORDER BY id
+	`, subscriptionsTable)
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := make([]*SubscriptionModel, 0)
+	for rows.Next() {
+		var subscription SubscriptionModel
+		err := rows.Scan(
+			&subscription.ID,
+			&subscription.URL,
+			&subscription.EventTypes,
+			&subscription.Secret,
+			&subscription.Active,
+			&subscription.CreatedAt,
+			&subscription.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, dto UpdateSubscriptionDTO) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET
+			url = COALESCE($2, url),
+			event_types = COALESCE($3, event_types),
+			secret = COALESCE($4, secret),
+			active = COALESCE($5, active),
+			updated_at = NOW()
+		WHERE id = $1
+	`, subscriptionsTable)
+
+	tag, err := r.db.Exec(ctx, query, dto.SubscriptionID, dto.URL, dto.EventTypes, dto.Secret, dto.Active)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrSubscriptionNotFound
+	}
+
+	logging.FromContext(ctx).Debug("webhook subscription updated with ID: ", dto.SubscriptionID)
+	return nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, subscriptionID int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, subscriptionsTable)
+
+	tag, err := r.db.Exec(ctx, query, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrSubscriptionNotFound
+	}
+
+	logging.FromContext(ctx).Debug("webhook subscription deleted with ID: ", subscriptionID)
+	return nil
+}
+
+// CreateDeliveries queues the event for every active subscription to its type that
+// it has not been queued for yet, returning the number of deliveries queued.
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, eventID, eventType string, payload []byte) (int64, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (subscription_id, event_id, event_type, payload)
+		SELECT id, $1::text, $2::text, $3::jsonb
+		FROM %s
+		WHERE active AND $2 = ANY(event_types)
+		ON CONFLICT (subscription_id, event_id) WHERE replay_of IS NULL DO NOTHING
+	`, deliveriesTable, subscriptionsTable)
+
+	tag, err := r.db.Exec(ctx, query, eventID, eventType, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDueDelivery takes the pending delivery of an active subscription whose attempt is due
+// the longest, or returns nil when there is none. The claimed delivery is postponed by lease,
+// so that other workers skip it while it is attempted and pick it up again should the worker
+// die midway. The claim is identified by the token of the returned delivery.
+func (r *WebhookRepository) ClaimDueDelivery(ctx context.Context, lease time.Duration) (*claimedDelivery, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		WITH claimed AS (
+			UPDATE %[1]s d SET next_attempt_at = NOW() + make_interval(secs => $1), claim_token = $2
+			WHERE d.id = (
+				SELECT due.id FROM %[1]s due
+				JOIN %[2]s s ON s.id = due.subscription_id AND s.active
+				WHERE due.status = 'pending' AND due.next_attempt_at <= NOW()
+				ORDER BY due.next_attempt_at
+				LIMIT 1
+				FOR UPDATE OF due SKIP LOCKED
+			)
+			RETURNING %[3]s, d.claim_token
+		)
+		SELECT d.*, s.url, s.secret
+		FROM claimed d
+		JOIN %[2]s s ON s.id = d.subscription_id
+	`, deliveriesTable, subscriptionsTable, deliveryColumns)
+
+	var delivery claimedDelivery
+	dest := append(deliveryDest(&delivery.DeliveryModel), &delivery.ClaimToken, &delivery.URL, &delivery.Secret)
+	err := r.db.QueryRow(ctx, query, lease.Seconds(), hex.EncodeToString(token)).Scan(dest...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// RecordAttempt stores the outcome of an attempt of the delivery and releases its claim.
+// It fails with ErrClaimLost when another worker has claimed the delivery since.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, delivery *claimedDelivery) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET
+			status = $2,
+			attempts = $3,
+			next_attempt_at = $4,
+			last_attempt_at = $5,
+			response_status = $6,
+			last_error = $7,
+			claim_token = NULL
+		WHERE id = $1 AND claim_token = $8
+	`, deliveriesTable)
+
+	tag, err := r.db.Exec(ctx, query,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.ClaimToken,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrClaimLost
+	}
+
+	logging.FromContext(ctx).Debug("webhook delivery attempted with ID: ", delivery.ID)
+	return nil
+}
+
+// GetDeliveries returns a page of deliveries of the subscription, newest first.
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, subscriptionID int, filter DeliveryFilter, page, limit int) ([]*DeliveryModel, *common.PaginationMetadata, error) {
+	page = max(1, page)
+	limit = min(50, max(1, limit))
+
+	totalQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s d
+		WHERE d.subscription_id = $1 AND ($2::text IS NULL OR d.status = $2)
+	`, deliveriesTable)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s d
+		WHERE d.subscription_id = $1 AND ($2::text IS NULL OR d.status = $2)
+		ORDER BY d.id DESC
+		LIMIT $3 OFFSET $4
+	`, deliveryColumns, deliveriesTable)
+
+	var totalCount int
+	if err := r.db.QueryRow(ctx, totalQuery, subscriptionID, filter.Status).Scan(&totalCount); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := common.CalculateMetadata(totalCount, page, limit)
+
+	rows, err := r.db.Query(ctx, query, subscriptionID, filter.Status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*DeliveryModel, 0)
+	for rows.Next() {
+		var delivery DeliveryModel
+		if err := rows.Scan(deliveryDest(&delivery)...); err != nil {
+			return nil, nil, err
+		}
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return deliveries, &metadata, nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, subscriptionID int, deliveryID int64) (*DeliveryModel, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s d
+		WHERE d.id = $1 AND d.subscription_id = $2
+	`, deliveryColumns, deliveriesTable)
+
+	var delivery DeliveryModel
+	err := r.db.QueryRow(ctx, query, deliveryID, subscriptionID).Scan(deliveryDest(&delivery)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// ReplayDelivery queues the payload of the delivery again as a new delivery, whatever
+// the outcome of the original one was.
+func (r *WebhookRepository) ReplayDelivery(ctx context.Context, subscriptionID int, deliveryID int64) (*DeliveryModel, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s AS d (subscription_id, event_id, event_type, payload, replay_of)
+		SELECT subscription_id, event_id, event_type, payload, id
+		FROM %[1]s
+		WHERE id = $1 AND subscription_id = $2
+		RETURNING %[2]s
+	`, deliveriesTable, deliveryColumns)
+
+	var delivery DeliveryModel
+	err := r.db.QueryRow(ctx, query, deliveryID, subscriptionID).Scan(deliveryDest(&delivery)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debug("webhook delivery replayed with ID: ", delivery.ID)
+	return &delivery, nil
+}
+
+// deliveryDest returns the destinations of deliveryColumns.
+func deliveryDest(delivery *DeliveryModel) []interface{} {
+	return []interface{}{
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastAttemptAt,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&delivery.ReplayOf,
+		&delivery.CreatedAt,
+	}
+}
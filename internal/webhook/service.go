@@ -0,0 +1,287 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"effective-mobile/go/config"
+	"effective-mobile/go/internal/common"
+	"effective-mobile/go/internal/health"
+	"effective-mobile/go/internal/logging"
+	"effective-mobile/go/internal/outbox"
+	"effective-mobile/go/internal/song"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// secretPrefix marks secrets generated by the service.
+const secretPrefix = "whsec_"
+
+const (
+	// maxRetryBackoff caps the delay between attempts.
+	maxRetryBackoff = 6 * time.Hour
+	// maxErrorLength limits the response excerpt kept for failed attempts.
+	maxErrorLength = 512
+)
+
+type WebhookService struct {
+	config *config.Config
+	repo   *WebhookRepository
+	client *http.Client
+	// wake cuts the wait for the next poll short when deliveries are queued.
+	wake      chan struct{}
+	heartbeat health.Heartbeat
+}
+
+func NewWebhookService(cfg *config.Config, repo *WebhookRepository) *WebhookService {
+	return &WebhookService{
+		config: cfg,
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.Webhooks.Timeout, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// CreateSubscription stores the subscription, generating its secret unless one is given.
+func (s *WebhookService) CreateSubscription(ctx context.Context, subscription *SubscriptionModel) error {
+	if err := validateEventTypes(subscription.EventTypes); err != nil {
+		return err
+	}
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 24)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+
+		subscription.Secret = secretPrefix + hex.EncodeToString(secret)
+	}
+
+	return s.repo.CreateSubscription(ctx, subscription)
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, subscriptionID int) (*SubscriptionModel, error) {
+	return s.repo.GetSubscription(ctx, subscriptionID)
+}
+
+func (s *WebhookService) GetSubscriptions(ctx context.Context) ([]*SubscriptionModel, error) {
+	return s.repo.GetSubscriptions(ctx)
+}
+
+func (s *WebhookService) UpdateSubscription(ctx context.Context, dto UpdateSubscriptionDTO) (*SubscriptionModel, error) {
+	if dto.EventTypes != nil {
+		if err := validateEventTypes(*dto.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateSubscription(ctx, dto); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSubscription(ctx, dto.SubscriptionID)
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, subscriptionID int) error {
+	return s.repo.DeleteSubscription(ctx, subscriptionID)
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, subscriptionID int, filter DeliveryFilter, page, limit int) ([]*DeliveryModel, *common.PaginationMetadata, error) {
+	if _, err := s.repo.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, nil, err
+	}
+
+	return s.repo.GetDeliveries(ctx, subscriptionID, filter, page, limit)
+}
+
+func (s *WebhookService) GetDelivery(ctx context.Context, subscriptionID int, deliveryID int64) (*DeliveryModel, error) {
+	return s.repo.GetDelivery(ctx, subscriptionID, deliveryID)
+}
+
+// ReplayDelivery sends the payload of the delivery once more as a new delivery.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, subscriptionID int, deliveryID int64) (*DeliveryModel, error) {
+	delivery, err := s.repo.ReplayDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return delivery, nil
+}
+
+// Publish queues the outbox event for the subscriptions to its type. Deliveries of an event
+// are queued once, however many times the event is published.
+func (s *WebhookService) Publish(ctx context.Context, event *outbox.Event) error {
+	body, err := json.Marshal(Payload{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	queued, err := s.repo.CreateDeliveries(ctx, event.ID, event.Type, body)
+	if err != nil {
+		return err
+	}
+
+	if queued > 0 {
+		s.notify()
+	}
+
+	return nil
+}
+
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run attempts due deliveries until the context is cancelled, polling for them
+// every Webhooks.PollInterval and right after deliveries are queued.
+func (s *WebhookService) Run(ctx context.Context) {
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).WithField("worker", "webhook_delivery"))
+
+	ticker := time.NewTicker(s.config.Webhooks.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Check fails while due deliveries cannot be claimed or the worker has stalled.
+// Failed deliveries are up to the subscribers and do not count.
+func (s *WebhookService) Check(ctx context.Context) error {
+	return s.heartbeat.Check(s.config.Health.WorkerStaleAfter)
+}
+
+// deliverDue attempts due deliveries one by one until none are left. Every delivery is claimed
+// right before its attempt, so that the lease only has to outlast a single attempt.
+func (s *WebhookService) deliverDue(ctx context.Context) {
+	// A claimed delivery is retried by any worker once the attempt has surely timed out.
+	lease := 2 * s.config.Webhooks.Timeout
+
+	for ctx.Err() == nil {
+		delivery, err := s.repo.ClaimDueDelivery(ctx, lease)
+		s.heartbeat.Beat(err)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to claim a webhook delivery: ", err)
+			return
+		}
+
+		if delivery == nil {
+			return
+		}
+
+		s.attempt(ctx, delivery)
+	}
+}
+
+// attempt sends the delivery once and records the outcome, scheduling a retry
+// with exponential backoff until Webhooks.MaxAttempts attempts have failed.
+func (s *WebhookService) attempt(ctx context.Context, delivery *claimedDelivery) {
+	status, err := s.send(ctx, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = DeliverySucceeded
+		delivery.LastError = ""
+	case delivery.Attempts >= s.config.Webhooks.MaxAttempts:
+		delivery.Status = DeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.NextAttemptAt = now.Add(retryBackoff(s.config.Webhooks.RetryBackoff, delivery.Attempts))
+		delivery.LastError = err.Error()
+	}
+
+	// The attempt has been made, so its outcome is stored even when shutting down.
+	err = s.repo.RecordAttempt(context.WithoutCancel(ctx), delivery)
+	switch {
+	case errors.Is(err, ErrClaimLost):
+		logging.FromContext(ctx).Warn("webhook delivery ", delivery.ID, " outlived its lease, its attempt is not recorded")
+	case err != nil:
+		logging.FromContext(ctx).Error("failed to record webhook delivery ", delivery.ID, ": ", err)
+	}
+}
+
+// send posts the payload to the subscription's URL, returning the response status, if any.
+// Any status other than 2xx fails the attempt.
+func (s *WebhookService) send(ctx context.Context, delivery *claimedDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d: %s", resp.StatusCode, excerpt)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// retryBackoff returns the delay after the given number of failed attempts.
+func retryBackoff(base time.Duration, attempts int) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxRetryBackoff)
+}
+
+func validateEventTypes(eventTypes []string) error {
+	if len(eventTypes) == 0 {
+		return fmt.Errorf("%w: no event types given", ErrUnknownEventType)
+	}
+
+	for _, eventType := range eventTypes {
+		if !slices.Contains(song.SongEventTypes, eventType) {
+			return fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+		}
+	}
+
+	return nil
+}
@@ -1 +1 @@
-DROP TABLE IF EXISTS SONGS;
\ No newline at end of file
+DROP TABLE IF EXISTS songs;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS song_activity_totals;
+DROP TABLE IF EXISTS song_activity_daily;
+DROP TABLE IF EXISTS song_likes;
+DROP TABLE IF EXISTS song_plays;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS song_plays (
+    id BIGSERIAL PRIMARY KEY,
+    song_id INT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    user_id VARCHAR(255),
+    played_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS song_plays_song_id_idx ON song_plays (song_id);
+
+CREATE TABLE IF NOT EXISTS song_likes (
+    song_id INT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (song_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS song_likes_user_id_idx ON song_likes (user_id);
+
+-- Daily rollups of plays and net likes per song, used by windowed charts.
+CREATE TABLE IF NOT EXISTS song_activity_daily (
+    song_id INT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    plays BIGINT NOT NULL DEFAULT 0,
+    likes BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (song_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS song_activity_daily_day_idx ON song_activity_daily (day);
+
+-- All-time totals per song, used by all-time charts.
+CREATE TABLE IF NOT EXISTS song_activity_totals (
+    song_id INT PRIMARY KEY REFERENCES songs (id) ON DELETE CASCADE,
+    plays BIGINT NOT NULL DEFAULT 0,
+    likes BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS song_activity_totals_plays_idx ON song_activity_totals (plays DESC);
+CREATE INDEX IF NOT EXISTS song_activity_totals_likes_idx ON song_activity_totals (likes DESC);
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS songs_song_id_idx ON songs (song, id);
+CREATE INDEX IF NOT EXISTS songs_group_id_idx ON songs ("group", id);
+CREATE INDEX IF NOT EXISTS songs_release_date_id_idx ON songs (release_date, id);
+CREATE INDEX IF NOT EXISTS songs_release_date_desc_group_song_id_idx ON songs (release_date DESC, "group", song, id);
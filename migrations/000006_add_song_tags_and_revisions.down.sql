@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS songs_count_revision ON songs;
+DROP FUNCTION IF EXISTS songs_count_revision();
+
+ALTER TABLE songs DROP COLUMN IF EXISTS revision;
+
+DROP TABLE IF EXISTS song_tags;
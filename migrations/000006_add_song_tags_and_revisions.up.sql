@@ -0,0 +1,30 @@
+-- Free-form tags of a song, stored lowercased.
+CREATE TABLE IF NOT EXISTS song_tags (
+    song_id INT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (song_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS song_tags_tag_idx ON song_tags (tag);
+
+-- Number of versions of the song, starting at 1 and counting every write that changed it.
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS revision INT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION songs_count_revision() RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.song, NEW."group", NEW.release_date, NEW."text", NEW.link)
+        IS DISTINCT FROM
+       (OLD.song, OLD."group", OLD.release_date, OLD."text", OLD.link) THEN
+        NEW.revision := OLD.revision + 1;
+    ELSE
+        NEW.revision := OLD.revision;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS songs_count_revision ON songs;
+CREATE TRIGGER songs_count_revision
+    BEFORE UPDATE ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_count_revision();
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS songs_sync_couplet_ids ON songs;
+DROP FUNCTION IF EXISTS songs_sync_couplet_ids();
+ALTER TABLE songs DROP COLUMN IF EXISTS couplet_ids;
+DROP SEQUENCE IF EXISTS song_couplet_id_seq;
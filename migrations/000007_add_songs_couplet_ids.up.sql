@@ -0,0 +1,27 @@
+CREATE SEQUENCE IF NOT EXISTS song_couplet_id_seq AS BIGINT;
+
+-- Stable IDs of the couplets, parallel to "text".
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS couplet_ids BIGINT[] NOT NULL DEFAULT '{}';
+
+-- Couplet IDs follow the lyrics by position. Writes changing the number of couplets
+-- without providing as many IDs, such as replacing the whole lyrics, renumber every couplet.
+CREATE OR REPLACE FUNCTION songs_sync_couplet_ids() RETURNS TRIGGER AS $$
+BEGIN
+    IF cardinality(NEW.couplet_ids) <> cardinality(NEW."text") THEN
+        NEW.couplet_ids := ARRAY(
+            SELECT nextval('song_couplet_id_seq') FROM generate_series(1, cardinality(NEW."text"))
+        );
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS songs_sync_couplet_ids ON songs;
+CREATE TRIGGER songs_sync_couplet_ids
+    BEFORE INSERT OR UPDATE ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_sync_couplet_ids();
+
+UPDATE songs SET couplet_ids = ARRAY(
+    SELECT nextval('song_couplet_id_seq') FROM generate_series(1, cardinality("text"))
+);
@@ -0,0 +1,9 @@
+-- Translated couplets, aligned with the original by the stable couplet IDs.
+CREATE TABLE IF NOT EXISTS song_translations (
+    song_id INT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    language VARCHAR(16) NOT NULL,
+    couplet_id BIGINT NOT NULL,
+    "text" TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (song_id, language, couplet_id)
+);
@@ -0,0 +1,11 @@
+-- Languages detected in song lyrics. Songs whose language could not be
+-- determined have a single row with the "und" language and zero confidence.
+CREATE TABLE IF NOT EXISTS song_languages (
+    song_id INT NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    language VARCHAR(16) NOT NULL,
+    confidence REAL NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (song_id, language)
+);
+
+CREATE INDEX IF NOT EXISTS song_languages_language_idx ON song_languages (language, song_id);
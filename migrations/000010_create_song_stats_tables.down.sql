@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS songs_stats_delete ON songs;
+DROP TRIGGER IF EXISTS songs_stats_update ON songs;
+DROP TRIGGER IF EXISTS songs_stats_insert ON songs;
+DROP FUNCTION IF EXISTS songs_stats_refresh();
+DROP FUNCTION IF EXISTS songs_stats_apply();
+DROP FUNCTION IF EXISTS song_stats_shard();
+DROP TABLE IF EXISTS song_stats_changes;
+DROP TABLE IF EXISTS song_stats_days;
+DROP TABLE IF EXISTS song_stats_years;
+DROP TABLE IF EXISTS song_stats_groups;
+DROP TABLE IF EXISTS song_stats_totals;
+ALTER TABLE songs DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,142 @@
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Summary tables behind library statistics, kept up to date by the triggers below.
+--
+-- Every new song changes the library totals and the count of the current day, so a single
+-- row for each would queue concurrent writes up until commit. These counters are spread over
+-- shards instead, picked by the connection, and summed on read. Concurrent transactions run
+-- on different connections, so they mostly update different rows. A shard may go negative
+-- when songs are deleted from another connection; only the sums are meaningful.
+CREATE OR REPLACE FUNCTION song_stats_shard() RETURNS SMALLINT AS $$
+    SELECT (pg_backend_pid() % 16)::SMALLINT;
+$$ LANGUAGE sql STABLE;
+
+CREATE TABLE IF NOT EXISTS song_stats_totals (
+    shard SMALLINT PRIMARY KEY,
+    songs BIGINT NOT NULL DEFAULT 0,
+    missing_lyrics BIGINT NOT NULL DEFAULT 0,
+    missing_links BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS song_stats_groups (
+    "group" VARCHAR(255) PRIMARY KEY,
+    songs BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS song_stats_groups_songs_idx ON song_stats_groups (songs DESC);
+
+CREATE TABLE IF NOT EXISTS song_stats_years (
+    year INT PRIMARY KEY,
+    songs BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS song_stats_days (
+    day DATE NOT NULL,
+    shard SMALLINT NOT NULL,
+    songs BIGINT NOT NULL,
+    PRIMARY KEY (day, shard)
+);
+
+-- Rows changed by a statement, +1 for new and -1 for old versions. They are written and
+-- removed within the changing transaction, so no other transaction ever sees them.
+CREATE UNLOGGED TABLE IF NOT EXISTS song_stats_changes (
+    "group" VARCHAR(255) NOT NULL,
+    year INT NOT NULL,
+    day DATE NOT NULL,
+    missing_lyrics BOOLEAN NOT NULL,
+    missing_link BOOLEAN NOT NULL,
+    sign INT NOT NULL
+);
+
+CREATE OR REPLACE FUNCTION songs_stats_apply() RETURNS VOID AS $$
+BEGIN
+    INSERT INTO song_stats_totals (shard, songs, missing_lyrics, missing_links)
+    SELECT song_stats_shard(), c.songs, c.missing_lyrics, c.missing_links
+    FROM (
+        SELECT
+            COALESCE(SUM(sign), 0) AS songs,
+            COALESCE(SUM(sign) FILTER (WHERE missing_lyrics), 0) AS missing_lyrics,
+            COALESCE(SUM(sign) FILTER (WHERE missing_link), 0) AS missing_links
+        FROM song_stats_changes
+    ) c
+    WHERE c.songs <> 0 OR c.missing_lyrics <> 0 OR c.missing_links <> 0
+    ON CONFLICT (shard) DO UPDATE SET
+        songs = song_stats_totals.songs + EXCLUDED.songs,
+        missing_lyrics = song_stats_totals.missing_lyrics + EXCLUDED.missing_lyrics,
+        missing_links = song_stats_totals.missing_links + EXCLUDED.missing_links;
+
+    INSERT INTO song_stats_groups ("group", songs)
+    SELECT "group", SUM(sign) FROM song_stats_changes GROUP BY "group" HAVING SUM(sign) <> 0
+    ON CONFLICT ("group") DO UPDATE SET songs = song_stats_groups.songs + EXCLUDED.songs;
+
+    INSERT INTO song_stats_years (year, songs)
+    SELECT year, SUM(sign) FROM song_stats_changes GROUP BY year HAVING SUM(sign) <> 0
+    ON CONFLICT (year) DO UPDATE SET songs = song_stats_years.songs + EXCLUDED.songs;
+
+    INSERT INTO song_stats_days (day, shard, songs)
+    SELECT day, song_stats_shard(), SUM(sign) FROM song_stats_changes GROUP BY day HAVING SUM(sign) <> 0
+    ON CONFLICT (day, shard) DO UPDATE SET songs = song_stats_days.songs + EXCLUDED.songs;
+
+    DELETE FROM song_stats_groups WHERE songs <= 0 AND "group" IN (SELECT "group" FROM song_stats_changes);
+    DELETE FROM song_stats_years WHERE songs <= 0 AND year IN (SELECT year FROM song_stats_changes);
+    DELETE FROM song_stats_days
+    WHERE songs = 0 AND shard = song_stats_shard() AND day IN (SELECT day FROM song_stats_changes);
+
+    DELETE FROM song_stats_changes;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION songs_stats_refresh() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO song_stats_changes
+        SELECT "group", EXTRACT(YEAR FROM release_date), (created_at AT TIME ZONE 'UTC')::date,
+            cardinality("text") = 0, link = '', 1
+        FROM new_rows;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        INSERT INTO song_stats_changes
+        SELECT "group", EXTRACT(YEAR FROM release_date), (created_at AT TIME ZONE 'UTC')::date,
+            cardinality("text") = 0, link = '', -1
+        FROM old_rows;
+    END IF;
+
+    PERFORM songs_stats_apply();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Statement-level triggers see every changed row at once, so bulk imports update
+-- the summary tables once per statement instead of once per row.
+DROP TRIGGER IF EXISTS songs_stats_insert ON songs;
+CREATE TRIGGER songs_stats_insert
+    AFTER INSERT ON songs REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION songs_stats_refresh();
+
+DROP TRIGGER IF EXISTS songs_stats_update ON songs;
+CREATE TRIGGER songs_stats_update
+    AFTER UPDATE ON songs REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION songs_stats_refresh();
+
+DROP TRIGGER IF EXISTS songs_stats_delete ON songs;
+CREATE TRIGGER songs_stats_delete
+    AFTER DELETE ON songs REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION songs_stats_refresh();
+
+INSERT INTO song_stats_totals (shard, songs, missing_lyrics, missing_links)
+SELECT 0, COUNT(*), COUNT(*) FILTER (WHERE cardinality("text") = 0), COUNT(*) FILTER (WHERE link = '')
+FROM songs
+ON CONFLICT (shard) DO NOTHING;
+
+INSERT INTO song_stats_groups ("group", songs)
+SELECT "group", COUNT(*) FROM songs GROUP BY "group"
+ON CONFLICT ("group") DO NOTHING;
+
+INSERT INTO song_stats_years (year, songs)
+SELECT EXTRACT(YEAR FROM release_date), COUNT(*) FROM songs GROUP BY 1
+ON CONFLICT (year) DO NOTHING;
+
+INSERT INTO song_stats_days (day, shard, songs)
+SELECT (created_at AT TIME ZONE 'UTC')::date, 0, COUNT(*) FROM songs GROUP BY 1
+ON CONFLICT (day, shard) DO NOTHING;
@@ -0,0 +1,20 @@
+CREATE OR REPLACE FUNCTION songs_count_revision() RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.song, NEW."group", NEW.release_date, NEW."text", NEW.link)
+        IS DISTINCT FROM
+       (OLD.song, OLD."group", OLD.release_date, OLD."text", OLD.link) THEN
+        NEW.revision := OLD.revision + 1;
+    ELSE
+        NEW.revision := OLD.revision;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS songs_explicit_idx;
+
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS explicit,
+    DROP COLUMN IF EXISTS explicit_override,
+    DROP COLUMN IF EXISTS explicit_detected;
@@ -0,0 +1,24 @@
+-- explicit_detected is set from the lyrics whenever they are written, while
+-- explicit_override is set by hand and takes precedence while it is not null.
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS explicit_detected BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS explicit_override BOOLEAN,
+    ADD COLUMN IF NOT EXISTS explicit BOOLEAN GENERATED ALWAYS AS (COALESCE(explicit_override, explicit_detected)) STORED;
+
+CREATE INDEX IF NOT EXISTS songs_explicit_idx ON songs (explicit, id);
+
+-- Setting the explicit flag by hand is a change of the song. The generated column
+-- is not computed yet when BEFORE triggers run, so its inputs are compared instead.
+CREATE OR REPLACE FUNCTION songs_count_revision() RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.song, NEW."group", NEW.release_date, NEW."text", NEW.link, NEW.explicit_detected, NEW.explicit_override)
+        IS DISTINCT FROM
+       (OLD.song, OLD."group", OLD.release_date, OLD."text", OLD.link, OLD.explicit_detected, OLD.explicit_override) THEN
+        NEW.revision := OLD.revision + 1;
+    ELSE
+        NEW.revision := OLD.revision;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,17 @@
+-- Events written in the same transaction as the changes they describe,
+-- published by the relay in seq order and kept for a while afterwards.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    seq BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL UNIQUE,
+    event_type VARCHAR(64) NOT NULL,
+    event_key VARCHAR(255) NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_events_published_at_idx ON outbox_events (published_at);
+
+-- Events may be published more than once, their webhook deliveries are queued once.
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_event_idx ON webhook_deliveries (subscription_id, event_id) WHERE replay_of IS NULL;
@@ -0,0 +1,3 @@
+-- Token of the worker that claimed the delivery last. An attempt is recorded only while
+-- the claim is still held, so a worker whose lease ran out cannot overwrite a later attempt.
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS claim_token VARCHAR(32);
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies migrations read from a file system, usually an embedded one.
+// Unlike migrate.Migrate it does not treat the absence of changes as an error.
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+func NewMigrator(fsys fs.FS, databaseURL string) (*Migrator, error) {
+	source, err := iofs.New(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate source err: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("migrate init err: %w", err)
+	}
+
+	return &Migrator{m: m}, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.m.Up())
+}
+
+// UpSteps applies the given number of pending migrations.
+func (m *Migrator) UpSteps(steps int) error {
+	return ignoreNoChange(m.m.Steps(steps))
+}
+
+// Down rolls back the given number of migrations, or all of them when steps is not positive.
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return ignoreNoChange(m.m.Down())
+	}
+
+	return ignoreNoChange(m.m.Steps(-steps))
+}
+
+// To migrates up or down to the given version.
+func (m *Migrator) To(version uint) error {
+	return ignoreNoChange(m.m.Migrate(version))
+}
+
+// Force sets the version without running migrations and clears the dirty flag.
+// A negative version marks the database as having no migrations applied.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Version returns the current version, which is zero when no migrations are applied.
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// LatestVersion returns the version of the last migration read from the file system,
+// which is zero when there are none.
+func LatestVersion(fsys fs.FS) (uint, error) {
+	source, err := iofs.New(fsys, ".")
+	if err != nil {
+		return 0, fmt.Errorf("migrate source err: %w", err)
+	}
+
+	defer source.Close()
+
+	version, err := source.First()
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		version = next
+	}
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return err
+}
@@ -7,12 +7,17 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
-func NewPostgresConnection(url string) (*pgxpool.Pool, error) {
+// NewPostgresConnection connects a pool, applying the given functions to its configuration first.
+func NewPostgresConnection(url string, configure ...func(*pgxpool.Config)) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(url)
 	if err != nil {
 		return nil, fmt.Errorf("pgxpool parse config err: %w", err)
 	}
 
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
 	conn, err := pgxpool.ConnectConfig(context.Background(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("pgxpool connect err: %w", err)
@@ -0,0 +1,173 @@
+// Package langdetect guesses the languages of a text offline. It tells scripts apart by their
+// letters and languages sharing a script by their stop words and distinctive letters,
+// which is enough for song lyrics but not for short phrases.
+package langdetect
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Result is a language found in a text along with the share of the text written in it.
+type Result struct {
+	// Language is an ISO 639-1 code.
+	Language   string
+	Confidence float64
+}
+
+// MinWords is the number of words below which a text is not analysed at all.
+const MinWords = 3
+
+// minConfidence drops languages making up only a negligible part of a text.
+const minConfidence = 0.1
+
+type script int
+
+const (
+	latin script = iota
+	cyrillic
+)
+
+type profile struct {
+	language string
+	script   script
+	// letters are letters the other languages of the script rarely use.
+	letters   string
+	stopWords map[string]bool
+}
+
+func newProfile(language string, s script, letters string, stopWords string) profile {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(stopWords) {
+		words[word] = true
+	}
+
+	return profile{language: language, script: s, letters: letters, stopWords: words}
+}
+
+// profiles are ordered by preference, the first profile of a script wins a tie.
+var profiles = []profile{
+	newProfile("en", latin, "", `the a an and you to of it in my me is that on your for be with i
+		all we this so are was but not can don't i'm it's just when what no at do`),
+	newProfile("de", latin, "äöüß", `der die das und ich du nicht ist ein eine mit zu mich dich
+		wir sie auf für es den dem sind mein dein noch nur auch wie`),
+	newProfile("fr", latin, "çœàèùâêîôûë", `le la les et je tu il elle de des un une que qui est pas
+		mon ton ma ta dans pour sur avec nous vous ce moi toi`),
+	newProfile("es", latin, "ñ¿¡áéíóú", `el la los las y yo tu que de en un una es no mi me te por
+		con para se lo como pero más su al`),
+	newProfile("it", latin, "àèìòù", `il lo la gli le e io tu che di un una è non mi ti per con
+		sono ma come del della nel alla`),
+	newProfile("ru", cyrillic, "ыэъё", `и в не на я что ты он она мы они с как а то это по но все
+		меня тебя мне тебе так только уже же был была где когда`),
+	newProfile("uk", cyrillic, "іїєґ", `і в не на я що ти він вона ми вони з як а то це по але все
+		мене тебе мені тобі так тільки вже же був була де коли`),
+}
+
+// StopWords returns the stop words of the language, or nil for an unsupported language.
+// The set is shared and must not be modified.
+func StopWords(language string) map[string]bool {
+	for _, p := range profiles {
+		if p.language == language {
+			return p.stopWords
+		}
+	}
+
+	return nil
+}
+
+// Words splits the text into lowercase words.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+}
+
+// Detect returns the languages of the text ordered by confidence, or nothing when the text
+// is too short or is written in an unsupported script. Confidences add up to at most 1.
+func Detect(text string) []Result {
+	words := Words(text)
+	if len(words) < MinWords {
+		return nil
+	}
+
+	counts := make(map[script]int)
+	scores := make([]int, len(profiles))
+	for _, word := range words {
+		s, ok := scriptOf(word)
+		if !ok {
+			continue
+		}
+
+		counts[s]++
+		for i, p := range profiles {
+			if p.script != s {
+				continue
+			}
+
+			if p.stopWords[word] {
+				scores[i] += 2
+			}
+
+			if p.letters != "" && strings.ContainsAny(word, p.letters) {
+				scores[i]++
+			}
+		}
+	}
+
+	// Words of a script go to the best-scoring language of the script,
+	// weighted by how clearly it stands out from the rest.
+	var results []Result
+	for s, count := range counts {
+		best, total := -1, 0
+		for i, p := range profiles {
+			if p.script != s {
+				continue
+			}
+
+			total += scores[i]
+			if best < 0 || scores[i] > scores[best] {
+				best = i
+			}
+		}
+
+		share := float64(count) / float64(len(words))
+
+		confidence := share / 2
+		if total > 0 {
+			confidence = share * float64(scores[best]) / float64(total)
+		}
+
+		if confidence >= minConfidence {
+			results = append(results, Result{Language: profiles[best].language, Confidence: confidence})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Confidence > results[j].Confidence
+	})
+
+	return results
+}
+
+// scriptOf returns the script most letters of the word belong to.
+func scriptOf(word string) (script, bool) {
+	var latinLetters, cyrillicLetters int
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latinLetters++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillicLetters++
+		}
+	}
+
+	switch {
+	case latinLetters == 0 && cyrillicLetters == 0:
+		return 0, false
+	case cyrillicLetters > latinLetters:
+		return cyrillic, true
+	default:
+		return latin, true
+	}
+}
@@ -0,0 +1,114 @@
+package langdetect
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var fixtures = []struct {
+	language string
+	text     string
+}{
+	{"en", "I walk the line because you are mine, and all of this is just what we do"},
+	{"de", "Ich bin nicht mehr da, und du bist mit mir auf der Straße, für dich"},
+	{"fr", "Je ne sais pas pourquoi tu es partie, mais la nuit est pour moi et toi"},
+	{"es", "Yo no sé por qué te fuiste, pero la noche es para mí y para ti, mañana"},
+	{"it", "Io non so perché sei andata, ma la notte è per me e per te, come sempre"},
+	{"ru", "Я не знаю, что ты скажешь мне, но только ты меня поймёшь, когда всё уже было"},
+	{"uk", "Я не знаю, що ти скажеш мені, але тільки ти мене зрозумієш, коли все вже було"},
+}
+
+func TestDetectFixtures(t *testing.T) {
+	for _, fixture := range fixtures {
+		t.Run(fixture.language, func(t *testing.T) {
+			results := Detect(fixture.text)
+			if len(results) == 0 {
+				t.Fatalf("Detect(%q) found no language", fixture.text)
+			}
+
+			if results[0].Language != fixture.language {
+				t.Fatalf("Detect(%q) = %+v, want %s first", fixture.text, results, fixture.language)
+			}
+
+			if results[0].Confidence <= 0.5 {
+				t.Errorf("confidence of %s is %v, want more than 0.5", fixture.language, results[0].Confidence)
+			}
+		})
+	}
+}
+
+func TestDetectMixedScripts(t *testing.T) {
+	text := "you are the one, you are the way to my heart and the sun, я не знаю что ты"
+
+	results := Detect(text)
+	if len(results) != 2 {
+		t.Fatalf("Detect(%q) = %+v, want two languages", text, results)
+	}
+
+	if results[0].Language != "en" || results[1].Language != "ru" {
+		t.Errorf("Detect(%q) = %+v, want en before ru", text, results)
+	}
+
+	var sum float64
+	for _, result := range results {
+		sum += result.Confidence
+	}
+
+	if sum > 1+1e-9 {
+		t.Errorf("confidences add up to %v, want at most 1", sum)
+	}
+}
+
+func TestDetectShortInputs(t *testing.T) {
+	for _, text := range []string{"", "   ", "hello", "hello world", "la la", "123 456 789", "!!! ??? ..."} {
+		if results := Detect(text); results != nil {
+			t.Errorf("Detect(%q) = %+v, want nothing", text, results)
+		}
+	}
+
+	if results := Detect("the one you"); len(results) == 0 {
+		t.Errorf("Detect of %d words found no language", MinWords)
+	}
+}
+
+func TestDetectUnsupportedScript(t *testing.T) {
+	for _, text := range []string{"東京 の 夜 は 長い", "Καλημέρα σας φίλοι μου όλοι"} {
+		if results := Detect(text); len(results) != 0 {
+			t.Errorf("Detect(%q) = %+v, want nothing", text, results)
+		}
+	}
+}
+
+func TestDetectWithoutStopWords(t *testing.T) {
+	// Words of a script with no clues about the language are split evenly between the
+	// first profile of the script and the rest.
+	results := Detect("blue yellow green orange purple")
+	if len(results) != 1 || results[0].Language != "en" || math.Abs(results[0].Confidence-0.5) > 1e-9 {
+		t.Errorf("Detect = %+v, want en with confidence 0.5", results)
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := Words("Don't STOP me now — I'm having   such a good time!\nÇa va?")
+	want := []string{"don't", "stop", "me", "now", "i'm", "having", "such", "a", "good", "time", "ça", "va"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Words = %q, want %q", got, want)
+	}
+}
+
+func TestStopWords(t *testing.T) {
+	for _, fixture := range fixtures {
+		if StopWords(fixture.language) == nil {
+			t.Errorf("StopWords(%q) = nil", fixture.language)
+		}
+	}
+
+	if !StopWords("en")["the"] {
+		t.Error(`StopWords("en") lacks "the"`)
+	}
+
+	if StopWords("xx") != nil {
+		t.Error(`StopWords("xx") is not nil`)
+	}
+}
@@ -0,0 +1,223 @@
+// Package profanity finds words of per-language word lists in a text and masks them.
+// Lists are plain text files named after the ISO 639-1 code of their language, holding
+// a word per line; a trailing * makes an entry match every word starting with it,
+// and lines starting with # are comments.
+package profanity
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//go:embed lists/*.txt
+var builtin embed.FS
+
+// MaskRune replaces every letter of a masked word.
+const MaskRune = '*'
+
+type list struct {
+	words    map[string]bool
+	prefixes []string
+}
+
+func (l *list) matches(word string) bool {
+	if l.words[word] {
+		return true
+	}
+
+	for _, prefix := range l.prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lists holds the word lists of every language.
+type Lists struct {
+	lists map[string]*list
+}
+
+// Default returns the built-in lists.
+func Default() *Lists {
+	lists, err := load(builtin, "lists")
+	if err != nil {
+		panic(fmt.Sprintf("profanity: built-in lists: %v", err))
+	}
+
+	return lists
+}
+
+// Load returns the built-in lists, with the lists found in dir replacing the built-in
+// lists of the same languages. An empty dir leaves the built-in lists as they are.
+func Load(dir string) (*Lists, error) {
+	lists := Default()
+	if dir == "" {
+		return lists, nil
+	}
+
+	custom, err := load(os.DirFS(dir), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	for language, l := range custom.lists {
+		lists.lists[language] = l
+	}
+
+	return lists, nil
+}
+
+func load(fsys fs.FS, dir string) (*Lists, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	lists := &Lists{lists: make(map[string]*list, len(files))}
+	for _, name := range files {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+
+		l, err := parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		language := strings.ToLower(strings.TrimSuffix(path.Base(name), ".txt"))
+		lists.lists[language] = l
+	}
+
+	return lists, nil
+}
+
+func parse(r io.Reader) (*list, error) {
+	l := &list{words: make(map[string]bool)}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		entry := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+			l.prefixes = append(l.prefixes, prefix)
+		} else {
+			l.words[entry] = true
+		}
+	}
+
+	return l, scanner.Err()
+}
+
+// Languages returns the languages having a list, sorted.
+func (l *Lists) Languages() []string {
+	languages := make([]string, 0, len(l.lists))
+	for language := range l.lists {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+	return languages
+}
+
+// Contains reports whether the text has a word of the lists of the languages.
+// Languages without a list are skipped, and no languages at all stand for every list.
+func (l *Lists) Contains(text string, languages []string) bool {
+	found := false
+	l.scan(text, languages, func(_, _ int) bool {
+		found = true
+		return false
+	})
+
+	return found
+}
+
+// Mask replaces the letters of words of the lists of the languages with MaskRune,
+// picking the lists like Contains does.
+func (l *Lists) Mask(text string, languages []string) string {
+	var b strings.Builder
+
+	last := 0
+	l.scan(text, languages, func(start, end int) bool {
+		b.WriteString(text[last:start])
+		b.WriteString(strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) {
+				return MaskRune
+			}
+
+			return r
+		}, text[start:end]))
+		last = end
+		return true
+	})
+
+	if last == 0 {
+		return text
+	}
+
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// scan calls fn with the byte offsets of every listed word of the text until fn returns false.
+// Words are runs of letters and apostrophes, as in langdetect.Words.
+func (l *Lists) scan(text string, languages []string, fn func(start, end int) bool) {
+	selected := make([]*list, 0, len(l.lists))
+	if len(languages) == 0 {
+		for _, list := range l.lists {
+			selected = append(selected, list)
+		}
+	}
+
+	for _, language := range languages {
+		if list, ok := l.lists[language]; ok {
+			selected = append(selected, list)
+		}
+	}
+
+	if len(selected) == 0 {
+		return
+	}
+
+	start := -1
+	for i, r := range text + " " {
+		if unicode.IsLetter(r) || r == '\'' {
+			if start < 0 {
+				start = i
+			}
+
+			continue
+		}
+
+		if start < 0 {
+			continue
+		}
+
+		word := strings.ToLower(strings.Trim(text[start:i], "'"))
+		for _, list := range selected {
+			if list.matches(word) {
+				if !fn(start, i) {
+					return
+				}
+
+				break
+			}
+		}
+
+		start = -1
+	}
+}
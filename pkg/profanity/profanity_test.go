@@ -0,0 +1,164 @@
+package profanity
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeLists writes the lists into a new directory, keyed by file name.
+func writeLists(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func testLists(t *testing.T) *Lists {
+	t.Helper()
+
+	lists, err := load(os.DirFS(writeLists(t, map[string]string{
+		"en.txt": "# comment\n\nDarn\nheck*\n",
+		"ru.txt": "блин*\n",
+		"de.txt": "mist\n",
+	})), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return lists
+}
+
+func TestMask(t *testing.T) {
+	lists := testLists(t)
+
+	tests := []struct {
+		name      string
+		text      string
+		languages []string
+		want      string
+	}{
+		{"word", "oh darn it", []string{"en"}, "oh **** it"},
+		{"case", "DARN, Darn and dArN", []string{"en"}, "****, **** and ****"},
+		{"whole words only", "darned darning undarn", []string{"en"}, "darned darning undarn"},
+		{"prefix", "heck hecking hecks", []string{"en"}, "**** ******* *****"},
+		{"prefix at word start only", "oh check that", []string{"en"}, "oh check that"},
+		{"punctuation", "darn!darn?(darn)\n-darn-", []string{"en"}, "****!****?(****)\n-****-"},
+		{"apostrophes", "'darn' heck's", []string{"en"}, "'****' ****'*"},
+		{"digits split words", "darn2go", []string{"en"}, "****2go"},
+		{"cyrillic", "Блин, блины и блинчики", []string{"ru"}, "****, ***** и ********"},
+		{"other language list", "oh darn it", []string{"ru"}, "oh darn it"},
+		{"several languages", "darn mist блин", []string{"en", "ru"}, "**** mist ****"},
+		{"unknown language", "oh darn it", []string{"xx"}, "oh darn it"},
+		{"every list", "darn mist блин", nil, "**** **** ****"},
+		{"nothing listed", "clean lyrics", nil, "clean lyrics"},
+		{"empty", "", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lists.Mask(tt.text, tt.languages); got != tt.want {
+				t.Errorf("Mask(%q, %q) = %q, want %q", tt.text, tt.languages, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	lists := testLists(t)
+
+	tests := []struct {
+		text      string
+		languages []string
+		want      bool
+	}{
+		{"oh DARN it", []string{"en"}, true},
+		{"undarned", []string{"en"}, false},
+		{"hecking", []string{"en"}, true},
+		{"блинчик", []string{"ru"}, true},
+		{"блинчик", []string{"en", "de"}, false},
+		{"mist", nil, true},
+		{"mist", []string{"xx"}, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := lists.Contains(tt.text, tt.languages); got != tt.want {
+			t.Errorf("Contains(%q, %q) = %v, want %v", tt.text, tt.languages, got, tt.want)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	lists := Default()
+
+	want := []string{"de", "en", "es", "fr", "it", "ru", "uk"}
+	if got := lists.Languages(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Languages() = %q, want %q", got, want)
+	}
+
+	if !lists.Contains("Bullshit", []string{"en"}) {
+		t.Error(`built-in English list does not match "Bullshit"`)
+	}
+
+	if lists.Contains("a perfectly clean line", nil) {
+		t.Error("built-in lists match a clean line")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeLists(t, map[string]string{
+		"en.txt":    "darn\n",
+		"XX.txt":    "zorp\n",
+		"notes.md":  "bullshit\n",
+		"empty.txt": "",
+	})
+
+	lists, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lists.Contains("bullshit", []string{"en"}) {
+		t.Error("custom English list did not replace the built-in one")
+	}
+
+	if !lists.Contains("darn", []string{"en"}) {
+		t.Error("custom English list is not used")
+	}
+
+	if !lists.Contains("scheiße", []string{"de"}) {
+		t.Error("built-in German list is not kept")
+	}
+
+	if !lists.Contains("zorp", []string{"xx"}) {
+		t.Error("list of a new language is not added with a lowercase code")
+	}
+
+	if lists.Contains("", []string{"empty"}) {
+		t.Error("empty list matches")
+	}
+
+	// Loading custom lists must not change the built-in ones.
+	if !Default().Contains("bullshit", []string{"en"}) {
+		t.Error("built-in English list was modified")
+	}
+}
+
+func TestLoadWithoutDir(t *testing.T) {
+	lists, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(lists.Languages(), Default().Languages()) {
+		t.Errorf("Load(\"\") languages = %q, want the built-in ones", lists.Languages())
+	}
+}